@@ -0,0 +1,583 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"trebek/internal/commands"
+	"trebek/internal/game"
+)
+
+// messenger is the part of irc.Client the game needs to talk to players.
+type messenger interface {
+	Privmsg(target, message string)
+}
+
+// trivia implements the trivia commands on top of the per-channel games.
+type trivia struct {
+	client   messenger
+	games    map[string]*game.Game // Keyed by lower-cased channel
+	stopChan chan struct{}
+}
+
+// game returns the game for the channel the command applies to.
+func (t *trivia) game(ctx *commands.Context) *game.Game {
+	return t.games[strings.ToLower(ctx.Channel)]
+}
+
+// registerCommands adds the trivia commands and help to the registry.
+func (t *trivia) registerCommands(r *commands.Registry) {
+	r.MustRegister(&commands.Command{
+		Name:    "hello",
+		Help:    "Say hello.",
+		Handler: t.hello,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "start",
+		Help:    "Start continuous trivia in this channel.",
+		Handler: t.start,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "match",
+		Usage:   "<questions> [final]",
+		Help:    "Play a match of a set number of questions and crown a winner. Add \"final\" to end it with Final Jeopardy.",
+		MinArgs: 1,
+		Handler: t.match,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "matches",
+		Help:    "Show the winners of the last three matches.",
+		Handler: t.matches,
+	})
+	r.MustRegister(&commands.Command{
+		Name:       "stop",
+		Help:       "Stop continuous trivia.",
+		Permission: commands.PermissionModerator,
+		Handler:    t.stop,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "question",
+		Aliases: []string{"q"},
+		Help:    "Ask a single question when continuous play is off.",
+		Handler: t.question,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "answer",
+		Aliases: []string{"a"},
+		Usage:   "<your answer>",
+		Help:    "Answer the current question.",
+		MinArgs: 1,
+		Handler: t.answer,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "dd",
+		Usage:   "<wager|max>",
+		Help:    "Claim the Daily Double and wager on it. Only you can answer it.",
+		MinArgs: 1,
+		Handler: t.dailyDouble,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "hint",
+		Help:    "Reveal part of the answer. Each hint costs a share of the clue's value.",
+		Handler: t.hint,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "score",
+		Help:    "Show your score.",
+		Handler: t.score,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "topscores",
+		Aliases: []string{"top"},
+		Usage:   "[today|week|month|year|2026-09|2026-W38|2026-09-15]",
+		Help:    "Show the five best scores of all time, or of a day, week, month or year.",
+		Handler: t.topScores,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "rating",
+		Usage:   "[nick]",
+		Help:    "Show a player's skill rating, which rises by beating others to answers.",
+		Handler: t.rating,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "ratings",
+		Help:    "Show the five best skill ratings.",
+		Handler: t.ratings,
+	})
+	r.MustRegister(&commands.Command{
+		Name:       "resetscoreboard",
+		Help:       "Wipe every score in this channel.",
+		Permission: commands.PermissionAdmin,
+		Handler:    t.resetScoreboard,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "skip",
+		Aliases: []string{"next"},
+		Help:    "Vote to skip the current question.",
+		Handler: t.skip,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "duel",
+		Usage:   "<nick> [best of] | accept | decline",
+		Help:    "Challenge a player to a head-to-head duel, or accept or decline a challenge.",
+		MinArgs: 1,
+		Handler: t.duel,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "record",
+		Usage:   "[nick]",
+		Help:    "Show a player's head-to-head duel record.",
+		Handler: t.record,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "team",
+		Usage:   "[create|join|leave|list] [name]",
+		Help:    "Show your team, create or join one, leave it, or list the team scores.",
+		Handler: t.team,
+	})
+	r.MustRegister(&commands.Command{
+		Name:    "get",
+		Usage:   "[setting]",
+		Help:    "Show a game setting, or list them all.",
+		Handler: t.getSetting,
+	})
+	r.MustRegister(&commands.Command{
+		Name:       "set",
+		Usage:      "<setting> <value>",
+		Help:       "Change a game setting in this channel. The change is saved.",
+		MinArgs:    2,
+		Permission: commands.PermissionAdmin,
+		Handler:    t.setSetting,
+	})
+	r.MustRegister(r.HelpCommand())
+}
+
+func (t *trivia) hello(ctx *commands.Context) {
+	ctx.Replyf("Hello, %s!", ctx.User)
+}
+
+func (t *trivia) start(ctx *commands.Context) {
+	triviaGame := t.game(ctx)
+	if t.dueling(ctx, triviaGame) {
+		return
+	}
+	if triviaGame.GetPlaying() {
+		ctx.Reply("Trivia is already running!")
+		return
+	}
+	triviaGame.SetPlaying(true)
+	ctx.Reply("Starting continuous trivia!")
+	go gameLoop(t.client, triviaGame, ctx.Prefix, t.stopChan)
+	askQuestion(t.client, triviaGame, ctx.Prefix) // Ask the first question immediately
+}
+
+func (t *trivia) stop(ctx *commands.Context) {
+	triviaGame := t.game(ctx)
+	if triviaGame.Dueling() {
+		triviaGame.ClearCurrentQuestion()
+		d := triviaGame.EndDuel(false)
+		ctx.Replyf("Duel between %s and %s stopped.", d.Challenger, d.Opponent)
+		return
+	}
+	if !triviaGame.GetPlaying() {
+		ctx.Reply("Trivia is not currently running.")
+		return
+	}
+	triviaGame.SetPlaying(false)
+	ctx.Reply("Stopping continuous trivia.")
+	finishMatch(t.client, triviaGame)
+}
+
+func (t *trivia) match(ctx *commands.Context) {
+	triviaGame := t.game(ctx)
+	if t.dueling(ctx, triviaGame) {
+		return
+	}
+	if triviaGame.GetPlaying() {
+		ctx.Reply("Trivia is already running!")
+		return
+	}
+	questions, err := strconv.Atoi(ctx.Args[0])
+	final := triviaGame.Settings().FinalJeopardy
+	if len(ctx.Args) > 1 {
+		final = strings.EqualFold(ctx.Args[1], "final")
+		if !final {
+			err = fmt.Errorf("unexpected %q", ctx.Args[1])
+		}
+	}
+	if err != nil {
+		ctx.Replyf("Usage: %smatch <questions> [final]", ctx.Prefix)
+		return
+	}
+	if err := triviaGame.StartMatch(questions, ctx.User, final); err != nil {
+		ctx.Replyf("Can't start a match: %v.", err)
+		return
+	}
+	triviaGame.SetPlaying(true)
+	if final {
+		ctx.Replyf("Starting a %d-question match, followed by Final Jeopardy!", questions)
+	} else {
+		ctx.Replyf("Starting a %d-question match!", questions)
+	}
+	go gameLoop(t.client, triviaGame, ctx.Prefix, t.stopChan)
+	askQuestion(t.client, triviaGame, ctx.Prefix)
+}
+
+func (t *trivia) question(ctx *commands.Context) {
+	triviaGame := t.game(ctx)
+	if t.dueling(ctx, triviaGame) {
+		return
+	}
+	if triviaGame.GetPlaying() {
+		ctx.Replyf("Trivia is running continuously. Please use %sstop to end continuous play if you want to ask questions manually.", ctx.Prefix)
+		return
+	}
+	askQuestion(t.client, triviaGame, ctx.Prefix)
+}
+
+func (t *trivia) answer(ctx *commands.Context) {
+	triviaGame := t.game(ctx)
+	if triviaGame.GetCurrentQuestion() == nil {
+		ctx.Replyf("No question is currently active. Type %squestion to get one.", ctx.Prefix)
+		return
+	}
+	if d := triviaGame.CurrentDuel(); d != nil && d.Accepted && !d.Includes(ctx.User) {
+		ctx.Replyf("Sorry, %s, only %s and %s can answer during the duel.", ctx.User, d.Challenger, d.Opponent)
+		return
+	}
+	if dd, ok := triviaGame.CurrentDailyDouble(); ok && dd.Player != ctx.User {
+		if dd.Player == "" {
+			ctx.Replyf("This is a Daily Double. Claim it first with %sdd <wager>.", ctx.Prefix)
+		} else {
+			ctx.Replyf("Sorry, %s, only %s can answer the Daily Double.", ctx.User, dd.Player)
+		}
+		return
+	}
+	handleAnswer(t.client, triviaGame, ctx.User, ctx.Target, ctx.Raw)
+}
+
+func (t *trivia) dailyDouble(ctx *commands.Context) {
+	triviaGame := t.game(ctx)
+	if _, ok := triviaGame.CurrentDailyDouble(); !ok {
+		ctx.Reply("There is no Daily Double to claim.")
+		return
+	}
+	var wager int
+	if strings.EqualFold(ctx.Args[0], "max") {
+		wager = triviaGame.MaxDailyDoubleWager(ctx.User)
+	} else {
+		var err error
+		if wager, err = parseWager(ctx.Raw); err != nil {
+			ctx.Replyf("Usage: %sdd <wager|max>", ctx.Prefix)
+			return
+		}
+	}
+	if err := triviaGame.ClaimDailyDouble(ctx.User, wager); err != nil {
+		ctx.Replyf("Sorry, %s, %v.", ctx.User, err)
+		return
+	}
+	q := triviaGame.GetCurrentQuestion()
+	t.client.Privmsg(triviaGame.GameChannel, fmt.Sprintf("%s wagers %s on the Daily Double! The clue: %s", ctx.User, game.FormatDollars(wager), q.Question))
+	startQuestionTimer(t.client, triviaGame) // The wager starts a fresh window to answer
+}
+
+func (t *trivia) hint(ctx *commands.Context) {
+	triviaGame := t.game(ctx)
+	if t.dueling(ctx, triviaGame) {
+		return
+	}
+	if _, ok := triviaGame.CurrentDailyDouble(); ok {
+		ctx.Reply("There are no hints on a Daily Double.")
+		return
+	}
+	hint, given := triviaGame.GetHint()
+	if !given {
+		ctx.Reply(hint) // Error message from GetHint
+		return
+	}
+	q := triviaGame.GetCurrentQuestion()
+	cost := triviaGame.HintCost(q)
+	ctx.Replyf("Hint for %s (-%s): %s", q.Category, game.FormatDollars(cost), hint)
+	triviaGame.Charge(ctx.User, cost, game.ReasonHint) // Subtract a share of the clue's value
+}
+
+func (t *trivia) score(ctx *commands.Context) {
+	ctx.Replyf("%s's score: %s", ctx.User, game.FormatDollars(t.game(ctx).Scoreboard.GetScore(ctx.User)))
+}
+
+func (t *trivia) topScores(ctx *commands.Context) {
+	sb := t.game(ctx).Scoreboard
+	var scores map[string]int
+	heading := "Top Scores: "
+	if len(ctx.Args) > 0 {
+		kind, label, err := game.ParsePeriod(ctx.Raw, time.Now())
+		if err != nil {
+			ctx.Replyf("Sorry, %v.", err)
+			return
+		}
+		scores, heading = sb.PeriodScores(label), fmt.Sprintf("Top Scores for %s %s: ", kind, label)
+	} else {
+		scores = sb.Scores()
+	}
+	if len(scores) == 0 {
+		ctx.Reply("No scores yet!")
+		return
+	}
+	response := heading
+	for i, p := range game.RankScores(scores) {
+		if i >= 5 { // Top 5
+			break
+		}
+		response += fmt.Sprintf("%s: %s ", p.Name, game.FormatDollars(p.Score))
+	}
+	ctx.Reply(response)
+}
+
+func (t *trivia) rating(ctx *commands.Context) {
+	player := ctx.User
+	if len(ctx.Args) > 0 {
+		player = ctx.Args[0]
+	}
+	ratings := t.game(ctx).Ratings
+	r := ratings.Get(player)
+	if r.Events == 0 {
+		ctx.Replyf("%s isn't rated yet. Ratings start at %d and change when you beat others to an answer.", player, game.InitialRating)
+		return
+	}
+	history := ratings.PlayerHistory(player)
+	last := ""
+	if len(history) > 0 {
+		last = fmt.Sprintf(", last change %+.0f", history[len(history)-1].Changes[player])
+	}
+	ctx.Replyf("%s's rating: %.0f (peak %.0f, %s rated%s)", player, r.Rating, r.Peak, plural(r.Events, "question"), last)
+}
+
+func (t *trivia) ratings(ctx *commands.Context) {
+	ranked := game.RankScores(t.game(ctx).Ratings.Ranking())
+	if len(ranked) == 0 {
+		ctx.Reply("Nobody is rated yet!")
+		return
+	}
+	parts := make([]string, 0, 5)
+	for i, p := range ranked {
+		if i >= 5 { // Top 5
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%d. %s %d", i+1, p.Name, p.Score))
+	}
+	ctx.Replyf("Ratings: %s", strings.Join(parts, ", "))
+}
+
+func (t *trivia) matches(ctx *commands.Context) {
+	history := t.game(ctx).MatchHistory()
+	if len(history) == 0 {
+		ctx.Reply("No matches have been played yet!")
+		return
+	}
+	parts := make([]string, 0, 3)
+	for i := len(history) - 1; i >= 0 && len(parts) < 3; i-- { // Latest 3
+		m := history[i]
+		winner := "nobody scored"
+		if standings := m.Standings(); len(standings) > 0 {
+			winner = fmt.Sprintf("%s won with %s", standings[0].Name, game.FormatDollars(standings[0].Score))
+		}
+		length := plural(m.Questions, "question")
+		if !m.Completed {
+			length = fmt.Sprintf("stopped after %d of %d", m.Asked, m.Questions)
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s (%s)", m.StartedAt.Format("Jan 2"), winner, length))
+	}
+	ctx.Replyf("Recent matches: %s", strings.Join(parts, "; "))
+}
+
+func (t *trivia) resetScoreboard(ctx *commands.Context) {
+	t.game(ctx).Scoreboard.Reset()
+	ctx.Reply("Scoreboard has been reset!")
+}
+
+func (t *trivia) getSetting(ctx *commands.Context) {
+	settings := t.game(ctx).Settings()
+	if len(ctx.Args) == 0 {
+		var values []string
+		for _, key := range game.SettingKeys() {
+			value, _ := settings.Get(key)
+			values = append(values, key+"="+value)
+		}
+		ctx.Replyf("Settings: %s", strings.Join(values, ", "))
+		return
+	}
+	value, err := settings.Get(ctx.Args[0])
+	if err != nil {
+		ctx.Replyf("%v. Settings: %s", err, strings.Join(game.SettingKeys(), ", "))
+		return
+	}
+	key := strings.ToLower(ctx.Args[0])
+	ctx.Replyf("%s = %s (%s)", key, value, game.SettingHelp(key))
+}
+
+func (t *trivia) setSetting(ctx *commands.Context) {
+	triviaGame := t.game(ctx)
+	key := strings.ToLower(ctx.Args[0])
+	if err := triviaGame.SetSetting(key, strings.Join(ctx.Args[1:], " ")); err != nil {
+		ctx.Replyf("Could not change %s: %v", key, err)
+		return
+	}
+	settings := triviaGame.Settings()
+	value, _ := settings.Get(key)
+	ctx.Replyf("%s is now %s.", key, value)
+}
+
+func (t *trivia) skip(ctx *commands.Context) {
+	triviaGame := t.game(ctx)
+	if t.dueling(ctx, triviaGame) {
+		return
+	}
+	if triviaGame.GetCurrentQuestion() == nil {
+		ctx.Reply("No question is currently active to skip.")
+		return
+	}
+	currentVotes, threshold, skipped := triviaGame.AddNextVote(ctx.User)
+	if !skipped {
+		ctx.Replyf("%s voted to skip. %d/%d votes to skip.", ctx.User, currentVotes, threshold)
+		return
+	}
+	ctx.Replyf("Question skipped! The answer was: %s", triviaGame.GetCurrentQuestion().Answer)
+	triviaGame.ClearCurrentQuestion()
+	if triviaGame.GetPlaying() {
+		triviaGame.AnswerGiven <- false // Signal to game loop to get next question
+	}
+}
+
+func (t *trivia) team(ctx *commands.Context) {
+	sb := t.game(ctx).Scoreboard
+	if len(ctx.Args) == 0 {
+		name := sb.TeamOf(ctx.User)
+		if name == "" {
+			ctx.Replyf("%s, you're not on a team. Join one with %steam join <name> or start one with %steam create <name>.", ctx.User, ctx.Prefix, ctx.Prefix)
+			return
+		}
+		team, _ := sb.Team(name)
+		ctx.Replyf("%s is on %s with %s: %s", ctx.User, name, game.FormatDollars(team.Score), strings.Join(team.Members, ", "))
+		return
+	}
+	name := strings.Join(ctx.Args[1:], " ")
+	switch strings.ToLower(ctx.Args[0]) {
+	case "create":
+		if err := sb.CreateTeam(name, ctx.User); err != nil {
+			ctx.Replyf("Can't create the team: %v.", err)
+			return
+		}
+		ctx.Replyf("%s created team %s.", ctx.User, sb.TeamOf(ctx.User))
+	case "join":
+		joined, err := sb.JoinTeam(name, ctx.User)
+		if err != nil {
+			ctx.Replyf("Can't join the team: %v.", err)
+			return
+		}
+		ctx.Replyf("%s joined %s.", ctx.User, joined)
+	case "leave":
+		left, err := sb.LeaveTeam(ctx.User)
+		if err != nil {
+			ctx.Replyf("Sorry, %s, %v.", ctx.User, err)
+			return
+		}
+		ctx.Replyf("%s left %s.", ctx.User, left)
+	case "list":
+		ranked := game.RankScores(sb.TeamScores())
+		if len(ranked) == 0 {
+			ctx.Replyf("No teams yet! Start one with %steam create <name>.", ctx.Prefix)
+			return
+		}
+		parts := make([]string, len(ranked))
+		for i, p := range ranked {
+			team, _ := sb.Team(p.Name)
+			parts[i] = fmt.Sprintf("%d. %s %s (%s)", i+1, p.Name, game.FormatDollars(p.Score), strings.Join(team.Members, ", "))
+		}
+		ctx.Replyf("Teams: %s", strings.Join(parts, ", "))
+	default:
+		ctx.Replyf("Usage: %steam [create|join|leave|list] [name]", ctx.Prefix)
+	}
+}
+
+// dueling tells the user a duel is under way, and returns true, if one is.
+// Commands that would disturb the duel check this first.
+func (t *trivia) dueling(ctx *commands.Context, triviaGame *game.Game) bool {
+	d := triviaGame.CurrentDuel()
+	if d == nil || !d.Accepted {
+		return false
+	}
+	ctx.Replyf("Not now, %s and %s are dueling!", d.Challenger, d.Opponent)
+	return true
+}
+
+func (t *trivia) duel(ctx *commands.Context) {
+	triviaGame := t.game(ctx)
+	switch strings.ToLower(ctx.Args[0]) {
+	case "accept":
+		d, interrupted, err := triviaGame.AcceptDuel(ctx.User)
+		if err != nil {
+			ctx.Replyf("Sorry, %s, %v.", ctx.User, err)
+			return
+		}
+		if interrupted != nil {
+			t.client.Privmsg(triviaGame.GameChannel, fmt.Sprintf("Trivia is stopped for a duel. The answer was: %s", interrupted.Answer))
+		}
+		ctx.Replyf("The duel is on: %s vs %s, best of %d! Only they can answer, and the first to answer correctly within %s takes the question.",
+			d.Challenger, d.Opponent, d.BestOf, triviaGame.Settings().DuelWindow)
+		askDuelQuestion(t.client, triviaGame)
+	case "decline":
+		d, err := triviaGame.DeclineDuel(ctx.User)
+		if err != nil {
+			ctx.Replyf("Sorry, %s, %v.", ctx.User, err)
+			return
+		}
+		if ctx.User == d.Challenger {
+			ctx.Replyf("%s withdrew the challenge to %s.", d.Challenger, d.Opponent)
+		} else {
+			ctx.Replyf("%s declined %s's challenge.", d.Opponent, d.Challenger)
+		}
+	default:
+		opponent, bestOf := ctx.Args[0], game.DefaultDuelLength
+		if len(ctx.Args) > 1 {
+			var err error
+			if bestOf, err = strconv.Atoi(ctx.Args[1]); err != nil {
+				ctx.Replyf("Usage: %sduel <nick> [best of]", ctx.Prefix)
+				return
+			}
+		}
+		if err := triviaGame.Challenge(ctx.User, opponent, bestOf); err != nil {
+			ctx.Replyf("Can't start a duel: %v.", err)
+			return
+		}
+		ctx.Replyf("%s challenges %s to a best-of-%d duel! %s, type %sduel accept within %d seconds.",
+			ctx.User, opponent, bestOf, opponent, ctx.Prefix, int(game.ChallengeTimeout.Seconds()))
+	}
+}
+
+func (t *trivia) record(ctx *commands.Context) {
+	player := ctx.User
+	if len(ctx.Args) > 0 {
+		player = ctx.Args[0]
+	}
+	records := t.game(ctx).HeadToHead(player)
+	if len(records) == 0 {
+		ctx.Replyf("%s hasn't finished any duels.", player)
+		return
+	}
+	opponents := make([]string, 0, len(records))
+	var total game.DuelRecord
+	for opponent, r := range records {
+		opponents = append(opponents, opponent)
+		total.Wins += r.Wins
+		total.Losses += r.Losses
+		total.Draws += r.Draws
+	}
+	sort.Strings(opponents)
+	for i, opponent := range opponents {
+		opponents[i] = fmt.Sprintf("%s vs %s", records[opponent], opponent)
+	}
+	ctx.Replyf("%s's duels: %s overall - %s", player, total, strings.Join(opponents, ", "))
+}
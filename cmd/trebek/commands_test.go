@@ -0,0 +1,644 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"trebek/internal/commands"
+	"trebek/internal/game"
+	"trebek/internal/question"
+)
+
+// fakeMessenger records messages instead of sending them to IRC.
+type fakeMessenger struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (f *fakeMessenger) Privmsg(target, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, target+" "+message)
+}
+
+func (f *fakeMessenger) take() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sent := f.sent
+	f.sent = nil
+	return sent
+}
+
+type staticQuestionSource struct {
+	questions []*question.Question
+}
+
+func (s *staticQuestionSource) Next() (*question.Question, error) {
+	if len(s.questions) == 0 {
+		return nil, io.EOF
+	}
+	q := s.questions[0]
+	s.questions = s.questions[1:]
+	return q, nil
+}
+
+func (s *staticQuestionSource) Close() error { return nil }
+
+// newTestTrivia builds a registry with the trivia commands for a single
+// channel, keeping scoreboard files in a temporary directory.
+func newTestTrivia(t *testing.T) (*commands.Registry, *fakeMessenger, *game.Game) {
+	t.Chdir(t.TempDir())
+	qs := &staticQuestionSource{questions: []*question.Question{
+		{Category: "RIVERS", Question: "It flows through Cairo", Answer: "the Nile", Money: "$400"},
+	}}
+	triviaGame := game.NewGame(qs, "#trivia")
+	triviaGame.Configure("daily_double_chance", "0") // Tests that want one turn it on
+	t.Cleanup(func() {
+		if triviaGame.QuestionTimer != nil {
+			triviaGame.QuestionTimer.Stop()
+		}
+	})
+
+	client := &fakeMessenger{}
+	r := commands.NewRegistry("!")
+	tr := &trivia{client: client, games: map[string]*game.Game{"#trivia": triviaGame}, stopChan: make(chan struct{})}
+	tr.registerCommands(r)
+	return r, client, triviaGame
+}
+
+// run dispatches a command line from user in #trivia.
+func run(r *commands.Registry, client *fakeMessenger, user, input string) bool {
+	ctx := &commands.Context{
+		User:    user,
+		Target:  "#trivia",
+		Channel: "#trivia",
+		Reply:   func(reply string) { client.Privmsg("#trivia", reply) },
+	}
+	return r.Dispatch(ctx, input)
+}
+
+func TestTriviaCommandsRegistered(t *testing.T) {
+	r, _, _ := newTestTrivia(t)
+	for _, name := range []string{"hello", "start", "stop", "question", "answer", "hint", "score", "topscores", "resetscoreboard", "skip", "get", "set", "match", "matches", "dd", "team", "duel", "record", "rating", "ratings", "help"} {
+		if r.Lookup(name) == nil {
+			t.Errorf("Command %s is not registered", name)
+		}
+	}
+	for _, name := range []string{"scoreboard", "starts"} {
+		if r.Lookup(name) != nil {
+			t.Errorf("%s should not match any command", name)
+		}
+	}
+}
+
+func TestQuestionAndAnswer(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+
+	run(r, client, "alice", "answer nile")
+	if sent := client.take(); len(sent) != 1 || !strings.Contains(sent[0], "No question is currently active") {
+		t.Errorf("Expected no-question reply, got %v", sent)
+	}
+
+	run(r, client, "alice", "question")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia Category: RIVERS for $400 - Question: It flows through Cairo" {
+		t.Errorf("Expected the question to be announced, got %v", sent)
+	}
+
+	run(r, client, "bob", "answer Amazon")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia Sorry, bob, that's not correct." {
+		t.Errorf("Expected a wrong-answer reply, got %v", sent)
+	}
+
+	run(r, client, "alice", "a The Nile")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia Correct, alice! The answer was: the Nile (+$400)" {
+		t.Errorf("Expected a correct-answer reply, got %v", sent)
+	}
+	if score := triviaGame.Scoreboard.GetScore("alice"); score != 400 {
+		t.Errorf("Expected alice to win the clue's $400, got %d", score)
+	}
+	if triviaGame.GetCurrentQuestion() != nil {
+		t.Error("Expected the question to be cleared after a correct answer")
+	}
+}
+
+func TestHintAndScore(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+	run(r, client, "alice", "question")
+	client.take()
+
+	run(r, client, "alice", "hint")
+	if sent := client.take(); len(sent) != 1 || !strings.HasPrefix(sent[0], "#trivia Hint for RIVERS (-$40): ") {
+		t.Errorf("Expected a hint, got %v", sent)
+	}
+	if score := triviaGame.Scoreboard.GetScore("alice"); score != -40 {
+		t.Errorf("Expected hint to cost 10%% of $400, score is %d", score)
+	}
+
+	run(r, client, "alice", "score")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia alice's score: -$40" {
+		t.Errorf("Unexpected score reply: %v", sent)
+	}
+}
+
+func TestTopScoresAndReset(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+
+	run(r, client, "alice", "topscores")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia No scores yet!" {
+		t.Errorf("Unexpected empty top scores reply: %v", sent)
+	}
+
+	triviaGame.Scoreboard.AddScore("alice", 200)
+	triviaGame.Scoreboard.AddScore("bob", 1600)
+	run(r, client, "alice", "top")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia Top Scores: bob: $1,600 alice: $200 " {
+		t.Errorf("Unexpected top scores reply: %v", sent)
+	}
+
+	run(r, client, "alice", "resetscoreboard")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia Sorry, alice, !resetscoreboard needs admin access." {
+		t.Errorf("Expected players to be refused, got %v", sent)
+	}
+	if len(triviaGame.Scoreboard.Scores()) != 2 {
+		t.Errorf("Scoreboard was reset by a player: %v", triviaGame.Scoreboard.Scores())
+	}
+
+	r.Authorize = func(ctx *commands.Context, perm commands.Permission) bool {
+		return ctx.User == "admin" && perm <= commands.PermissionAdmin
+	}
+	run(r, client, "admin", "resetscoreboard")
+	client.take()
+	if len(triviaGame.Scoreboard.Scores()) != 0 {
+		t.Errorf("Expected an empty scoreboard, got %v", triviaGame.Scoreboard.Scores())
+	}
+}
+
+func TestSkipVotes(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+	run(r, client, "alice", "question")
+	client.take()
+
+	run(r, client, "alice", "skip")
+	run(r, client, "bob", "next")
+	if sent := client.take(); len(sent) != 2 || sent[1] != "#trivia bob voted to skip. 2/3 votes to skip." {
+		t.Errorf("Unexpected skip replies: %v", sent)
+	}
+	run(r, client, "carol", "skip")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia Question skipped! The answer was: the Nile" {
+		t.Errorf("Expected the question to be skipped, got %v", sent)
+	}
+	if triviaGame.GetCurrentQuestion() != nil {
+		t.Error("Expected the question to be cleared after skipping")
+	}
+}
+
+func TestSetAndGet(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+
+	run(r, client, "alice", "set skip_votes 2")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia Sorry, alice, !set needs admin access." {
+		t.Errorf("Expected players to be refused, got %v", sent)
+	}
+
+	r.Authorize = func(ctx *commands.Context, perm commands.Permission) bool {
+		return ctx.User == "admin" && perm <= commands.PermissionAdmin
+	}
+	run(r, client, "admin", "set skip_votes 2")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia skip_votes is now 2." {
+		t.Errorf("Unexpected set reply: %v", sent)
+	}
+	if votes := triviaGame.Settings().SkipVotes; votes != 2 {
+		t.Errorf("Expected 2 votes to skip, got %d", votes)
+	}
+	run(r, client, "admin", "set question_timeout forever")
+	if sent := client.take(); len(sent) != 1 || !strings.HasPrefix(sent[0], "#trivia Could not change question_timeout: ") {
+		t.Errorf("Expected a validation error, got %v", sent)
+	}
+
+	run(r, client, "alice", "get SKIP_VOTES")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia skip_votes = 2 (votes needed to skip a question)" {
+		t.Errorf("Unexpected get reply: %v", sent)
+	}
+	run(r, client, "alice", "get")
+	if sent := client.take(); len(sent) != 1 || !strings.Contains(sent[0], "question_timeout=30s") {
+		t.Errorf("Expected every setting to be listed, got %v", sent)
+	}
+	run(r, client, "alice", "get color")
+	if sent := client.take(); len(sent) != 1 || !strings.HasPrefix(sent[0], `#trivia unknown setting "color"`) {
+		t.Errorf("Expected an unknown-setting reply, got %v", sent)
+	}
+}
+
+func TestMatchResults(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+	run(r, client, "alice", "matches")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia No matches have been played yet!" {
+		t.Errorf("Expected no matches yet, got %v", sent)
+	}
+	if err := triviaGame.StartMatch(1, "alice", false); err != nil {
+		t.Fatalf("StartMatch failed: %v", err)
+	}
+
+	askQuestion(client, triviaGame, "!")
+	if sent := client.take(); len(sent) != 1 || !strings.HasPrefix(sent[0], "#trivia Question 1 of 1 - Category: RIVERS") {
+		t.Errorf("Expected the match question to be numbered, got %v", sent)
+	}
+	handleAnswer(client, triviaGame, "bob", "#trivia", "Nile")
+	client.take()
+
+	askQuestion(client, triviaGame, "!")
+	sent := client.take()
+	if len(sent) != 3 || sent[0] != "#trivia Match over after 1 question!" || sent[1] != "#trivia Winner: bob with $400 (1 correct)" ||
+		!strings.HasPrefix(sent[2], "#trivia 1 of 1 question answered by 1 player in ") {
+		t.Errorf("Unexpected match results: %q", sent)
+	}
+	if history := triviaGame.MatchHistory(); len(history) != 1 || history[0].Scores["bob"] != 400 {
+		t.Errorf("Expected the match to be saved, got %+v", history)
+	}
+	run(r, client, "alice", "matches")
+	if sent := client.take(); len(sent) != 1 || !strings.HasPrefix(sent[0], "#trivia Recent matches: ") ||
+		!strings.HasSuffix(sent[0], ": bob won with $400 (1 question)") {
+		t.Errorf("Unexpected match history: %v", sent)
+	}
+}
+
+func TestMatchCommand(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+
+	run(r, client, "alice", "match lots")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia Usage: !match <questions> [final]" {
+		t.Errorf("Expected a usage reply, got %v", sent)
+	}
+	run(r, client, "alice", "match 500")
+	if sent := client.take(); len(sent) != 1 || !strings.HasPrefix(sent[0], "#trivia Can't start a match: ") {
+		t.Errorf("Expected an oversized match to be refused, got %v", sent)
+	}
+
+	run(r, client, "alice", "match 3")
+	if sent := client.take(); len(sent) != 2 || sent[0] != "#trivia Starting a 3-question match!" {
+		t.Errorf("Expected the match to start, got %v", sent)
+	}
+	r.Authorize = func(ctx *commands.Context, perm commands.Permission) bool { return true }
+	run(r, client, "alice", "stop")
+	if sent := client.take(); len(sent) != 3 || sent[1] != "#trivia Match stopped after 1 of 3 questions." || sent[2] != "#trivia Nobody scored this match." {
+		t.Errorf("Expected the match to be stopped, got %v", sent)
+	}
+	if triviaGame.GetPlaying() || triviaGame.CurrentMatch() != nil {
+		t.Error("Expected play to end with the match")
+	}
+}
+
+func TestFinalJeopardyFlow(t *testing.T) {
+	t.Chdir(t.TempDir())
+	originalPause := finalRevealPause
+	finalRevealPause = 0
+	defer func() { finalRevealPause = originalPause }()
+
+	qs := &staticQuestionSource{questions: []*question.Question{
+		{Category: "RIVERS", Question: "It flows through Cairo", Answer: "the Nile", Money: "$400"},
+	}}
+	triviaGame := game.NewGame(qs, "#trivia")
+	qs.questions = append(qs.questions, &question.Question{Category: "WORLD CAPITALS", Question: "It's the capital of Australia", Answer: "Canberra"})
+	client := &fakeMessenger{}
+	triviaGame.StartMatch(1, "alice", true)
+	triviaGame.StartRound()
+	triviaGame.AwardCorrect("alice", 1000)
+	triviaGame.AwardCorrect("bob", 400)
+	triviaGame.ClearCurrentQuestion()
+
+	if !startFinal(client, triviaGame) {
+		t.Fatal("Expected Final Jeopardy to start")
+	}
+	if sent := client.take(); len(sent) != 2 || sent[0] != "#trivia It's time for Final Jeopardy! The category is: WORLD CAPITALS" ||
+		!strings.HasPrefix(sent[1], "#trivia Finalists alice ($1,000), bob ($400): send me your wager privately") {
+		t.Errorf("Unexpected Final Jeopardy announcement: %q", sent)
+	}
+
+	handleFinalMessage(client, triviaGame, "alice", "wager $1,500")
+	handleFinalMessage(client, triviaGame, "alice", "wager lots")
+	handleFinalMessage(client, triviaGame, "alice", "wager 500")
+	handleFinalMessage(client, triviaGame, "bob", "400")
+	expected := []string{
+		"alice Sorry, you can wager between $0 and $1,000.",
+		`alice Send your wager as a number, e.g. "wager 500".`,
+		"alice Your wager of $500 is in. You can change it until wagers close.",
+		"bob Your wager of $400 is in. You can change it until wagers close.",
+	}
+	if sent := client.take(); strings.Join(sent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected wager replies: %q", sent)
+	}
+
+	triviaGame.CloseWagers()
+	handleFinalMessage(client, triviaGame, "alice", "Sydney")
+	handleFinalMessage(client, triviaGame, "bob", "Canberra")
+	client.take()
+
+	revealFinal(client, triviaGame)
+	sent := client.take()
+	expected = []string{
+		"#trivia Time's up! Let's see what our finalists wrote.",
+		`#trivia bob wrote "Canberra": correct! Wager: $400, score: $400 -> $800`,
+		`#trivia alice wrote "Sydney": incorrect! Wager: $500, score: $1,000 -> $500`,
+		"#trivia The correct response was: Canberra",
+		"#trivia Match over after 1 question!",
+		"#trivia Winner: bob with $800 (1 correct)",
+		"#trivia Runner-up: alice with $500 (1 correct)",
+	}
+	if len(sent) != len(expected)+1 || strings.Join(sent[:len(expected)], "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected Final Jeopardy reveal: %q", sent)
+	}
+}
+
+func TestFinalInput(t *testing.T) {
+	r, _, _ := newTestTrivia(t)
+	tests := []struct {
+		message, input string
+		ok             bool
+	}{
+		{"wager 500", "wager 500", true},
+		{"1500", "1500", true},
+		{"Canberra", "Canberra", true},
+		{"answer Canberra", "Canberra", true},
+		{"!answer Canberra", "Canberra", true},
+		{"help", "", false},
+		{"!score", "", false},
+		{"stop", "", false},
+	}
+	for _, tt := range tests {
+		if input, ok := finalInput(r, tt.message); input != tt.input || ok != tt.ok {
+			t.Errorf("finalInput(%q) = %q, %v, want %q, %v", tt.message, input, ok, tt.input, tt.ok)
+		}
+	}
+}
+
+func TestDailyDouble(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+	triviaGame.Configure("daily_double_chance", "100")
+	triviaGame.SetPlaying(true)
+	triviaGame.AwardCorrect("bob", 2000)
+
+	askQuestion(client, triviaGame, "!")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia Daily Double in RIVERS! The first to claim it with !dd <wager> answers alone, wagering up to $400 or their score." {
+		t.Errorf("Expected the clue to stay hidden, got %v", sent)
+	}
+
+	run(r, client, "alice", "answer the Nile")
+	run(r, client, "alice", "hint")
+	run(r, client, "alice", "dd 1000")
+	run(r, client, "bob", "dd max")
+	run(r, client, "alice", "dd 100")
+	expected := []string{
+		"#trivia This is a Daily Double. Claim it first with !dd <wager>.",
+		"#trivia There are no hints on a Daily Double.",
+		"#trivia Sorry, alice, you can wager between $5 and $400.",
+		"#trivia bob wagers $2,000 on the Daily Double! The clue: It flows through Cairo",
+		"#trivia Sorry, alice, bob already claimed the Daily Double.",
+	}
+	if sent := client.take(); strings.Join(sent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected Daily Double claim: %q", sent)
+	}
+
+	handleAnswer(client, triviaGame, "alice", "#trivia", "the Nile") // Ignored: not alice's to answer
+	go handleAnswer(client, triviaGame, "bob", "#trivia", "Nile")
+	if answered := <-triviaGame.AnswerGiven; !answered {
+		t.Error("Expected the answer to be signalled to the game loop")
+	}
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia Correct, bob! The answer was: the Nile (+$2,000)" {
+		t.Errorf("Unexpected Daily Double answer: %q", sent)
+	}
+	if score := triviaGame.Scoreboard.GetScore("bob"); score != 4000 {
+		t.Errorf("Expected bob to win the wager, got %d", score)
+	}
+	if _, ok := triviaGame.CurrentDailyDouble(); ok {
+		t.Error("Expected the Daily Double to be over")
+	}
+}
+
+func TestDailyDoubleTakesOneResponse(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+	triviaGame.Configure("daily_double_chance", "100")
+	triviaGame.SetPlaying(true)
+	triviaGame.AwardCorrect("bob", 2000)
+	askQuestion(client, triviaGame, "!")
+	run(r, client, "bob", "dd 500")
+	client.take()
+
+	go handleAnswer(client, triviaGame, "bob", "#trivia", "the Amazon")
+	if answered := <-triviaGame.AnswerGiven; !answered {
+		t.Error("Expected the response to be signalled to the game loop")
+	}
+	run(r, client, "bob", "answer the Nile") // Too late: the clue is closed
+	expected := []string{
+		"#trivia Sorry, bob, that's not correct. The answer was: the Nile (-$500)",
+		"#trivia No question is currently active. Type !question to get one.",
+	}
+	if sent := client.take(); strings.Join(sent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected Daily Double responses: %q", sent)
+	}
+	if score := triviaGame.Scoreboard.GetScore("bob"); score != 1500 {
+		t.Errorf("Expected bob to lose the wager once, got %d", score)
+	}
+	if q := triviaGame.GetCurrentQuestion(); q != nil {
+		t.Errorf("Expected the clue to be closed, got %v", q)
+	}
+}
+
+func TestTeamCommand(t *testing.T) {
+	r, client, _ := newTestTrivia(t)
+
+	run(r, client, "alice", "team")
+	run(r, client, "alice", "team list")
+	run(r, client, "alice", "team create Accounting")
+	run(r, client, "bob", "team join accounting")
+	run(r, client, "bob", "team create Accounting")
+	run(r, client, "carol", "team join Sales")
+	run(r, client, "alice", "team")
+	run(r, client, "bob", "team leave")
+	run(r, client, "bob", "team dance")
+	run(r, client, "alice", "team list")
+	expected := []string{
+		"#trivia alice, you're not on a team. Join one with !team join <name> or start one with !team create <name>.",
+		"#trivia No teams yet! Start one with !team create <name>.",
+		"#trivia alice created team Accounting.",
+		"#trivia bob joined Accounting.",
+		"#trivia Can't create the team: there is already a team called Accounting.",
+		"#trivia Can't join the team: there is no team called Sales.",
+		"#trivia alice is on Accounting with $0: alice, bob",
+		"#trivia bob left Accounting.",
+		"#trivia Usage: !team [create|join|leave|list] [name]",
+		"#trivia Teams: 1. Accounting $0 (alice)",
+	}
+	if sent := client.take(); strings.Join(sent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected team replies: %q", sent)
+	}
+}
+
+func TestTeamWindow(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+	triviaGame.Configure("team_mode", "on")
+	triviaGame.Configure("team_window", "1m")
+	run(r, client, "alice", "team create Accounting")
+	run(r, client, "bob", "team join Accounting")
+	run(r, client, "alice", "question")
+	client.take()
+
+	handleAnswer(client, triviaGame, "alice", "alice", "the Nile")
+	handleAnswer(client, triviaGame, "bob", "bob", "Nile")
+	handleAnswer(client, triviaGame, "carol", "carol", "nile")
+	expected := []string{
+		"#trivia Correct, alice (Accounting)! (+$400)",
+		"#trivia alice (Accounting) got it first! Other teams have 1m0s to answer too; send me your answer privately to keep it secret, e.g. \"answer <text>\".",
+		"bob Correct, bob, but Accounting already scored on this question.",
+		"#trivia Correct, carol! (+$400)",
+	}
+	if sent := client.take(); strings.Join(sent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected team window answers: %q", sent)
+	}
+	if triviaGame.GetCurrentQuestion() == nil {
+		t.Error("Expected the question to stay open during the window")
+	}
+	run(r, client, "alice", "team list")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia Teams: 1. Accounting $400 (alice, bob)" {
+		t.Errorf("Expected the team to be credited once, got %v", sent)
+	}
+}
+
+func TestDuel(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+
+	run(r, client, "alice", "duel bob 2")
+	run(r, client, "alice", "duel bob 1")
+	run(r, client, "carol", "duel accept")
+	run(r, client, "bob", "duel accept")
+	expected := []string{
+		"#trivia Can't start a duel: a duel must be best of an odd number of questions up to 15.",
+		"#trivia alice challenges bob to a best-of-1 duel! bob, type !duel accept within 60 seconds.",
+		"#trivia Sorry, carol, nobody has challenged you.",
+		"#trivia The duel is on: alice vs bob, best of 1! Only they can answer, and the first to answer correctly within 20s takes the question.",
+		"#trivia Duel question 1 of 1 (alice 0, bob 0) - Category: RIVERS - Question: It flows through Cairo",
+	}
+	if sent := client.take(); strings.Join(sent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected duel start: %q", sent)
+	}
+
+	run(r, client, "carol", "start")
+	run(r, client, "carol", "answer the Nile")
+	handleAnswer(client, triviaGame, "carol", "#trivia", "the Nile") // Ignored: carol isn't dueling
+	handleAnswer(client, triviaGame, "alice", "#trivia", "the Amazon")
+	handleAnswer(client, triviaGame, "bob", "#trivia", "Nile")
+	expected = []string{
+		"#trivia Not now, alice and bob are dueling!",
+		"#trivia Sorry, carol, only alice and bob can answer during the duel.",
+		"#trivia Sorry, alice, that's not correct.",
+		"#trivia Correct, bob! The answer was: the Nile (alice 0, bob 1)",
+		"#trivia bob wins the duel against alice, 1-0!",
+		"#trivia Head to head, alice is now 0-1 against bob.",
+	}
+	if sent := client.take(); strings.Join(sent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected duel: %q", sent)
+	}
+
+	run(r, client, "alice", "record bob")
+	run(r, client, "alice", "record carol")
+	if sent := client.take(); len(sent) != 2 || sent[0] != "#trivia bob's duels: 1-0 overall - 1-0 vs alice" || sent[1] != "#trivia carol hasn't finished any duels." {
+		t.Errorf("Unexpected records: %q", sent)
+	}
+}
+
+func TestRatings(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+
+	run(r, client, "alice", "ratings")
+	if sent := client.take(); len(sent) != 1 || sent[0] != "#trivia Nobody is rated yet!" {
+		t.Errorf("Expected no ratings yet, got %v", sent)
+	}
+	run(r, client, "alice", "question")
+	run(r, client, "bob", "answer Amazon")
+	run(r, client, "carol", "answer Congo")
+	run(r, client, "alice", "answer Nile")
+	client.take()
+
+	run(r, client, "alice", "rating")
+	run(r, client, "alice", "rating bob")
+	run(r, client, "alice", "rating dave")
+	run(r, client, "alice", "ratings")
+	expected := []string{
+		"#trivia alice's rating: 1516 (peak 1516, 1 question rated, last change +16)",
+		"#trivia bob's rating: 1492 (peak 1500, 1 question rated, last change -8)",
+		"#trivia dave isn't rated yet. Ratings start at 1500 and change when you beat others to an answer.",
+		"#trivia Ratings: 1. alice 1516, 2. bob 1492, 3. carol 1492",
+	}
+	sent := client.take()
+	if strings.Join(sent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected ratings: %q", sent)
+	}
+	if history := triviaGame.Ratings.PlayerHistory("carol"); len(history) != 1 || history[0].Winner != "alice" {
+		t.Errorf("Expected the question in carol's rating history, got %+v", history)
+	}
+}
+
+func TestPeriodLeaderboards(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+	triviaGame.Scoreboard.AddScore("alice", 400)
+	triviaGame.Scoreboard.AddScore("bob", 1200)
+	triviaGame.Scoreboard.AddScore("carol", 800)
+	triviaGame.Scoreboard.AddScore("dave", 200)
+
+	now := time.Now()
+	run(r, client, "alice", "topscores month")
+	run(r, client, "alice", "topscores 1999-01")
+	run(r, client, "alice", "topscores fortnight")
+	expected := []string{
+		fmt.Sprintf("#trivia Top Scores for month %s: bob: $1,200 carol: $800 alice: $400 dave: $200 ", game.PeriodLabel("month", now)),
+		"#trivia No scores yet!",
+		`#trivia Sorry, "fortnight" is not a period like week, month, 2026-09 or 2026-W38.`,
+	}
+	if sent := client.take(); strings.Join(sent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected period leaderboards: %q", sent)
+	}
+
+	// Only periods that ended, of the kinds chosen, are announced
+	triviaGame.Configure("announce_winners", "year")
+	announcePeriodWinners(client, triviaGame, now, now)
+	if sent := client.take(); len(sent) != 0 {
+		t.Errorf("Expected no announcement while the year is under way, got %q", sent)
+	}
+	announcePeriodWinners(client, triviaGame, now, now.AddDate(1, 0, 0))
+	expected = []string{
+		fmt.Sprintf("#trivia That's the end of year %d! The winner is bob with $1,200, ahead of carol ($800) and alice ($400).", now.Year()),
+	}
+	if sent := client.take(); strings.Join(sent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected winners: %q", sent)
+	}
+}
+
+func TestPrivateChannel(t *testing.T) {
+	channels := []string{"#trivia", "#quiz"}
+	members := map[string]string{"alice": "#quiz", "bob": "#trivia"}
+	onChannel := func(channel, nick string) bool { return members[nick] == channel }
+	for user, expected := range map[string]string{"alice": "#quiz", "bob": "#trivia", "carol": "#trivia"} {
+		if got := privateChannel(channels, user, onChannel); got != expected {
+			t.Errorf("privateChannel(%s) = %q, expected %q", user, got, expected)
+		}
+	}
+}
+
+func TestPrivateAnswerAnnouncedInChannel(t *testing.T) {
+	r, client, triviaGame := newTestTrivia(t)
+	run(r, client, "alice", "question")
+	client.take()
+
+	handleAnswer(client, triviaGame, "alice", "alice", "the Amazon")
+	handleAnswer(client, triviaGame, "alice", "alice", "the Nile")
+	expected := []string{
+		"alice Sorry, alice, that's not correct.",
+		"#trivia Correct, alice! The answer was: the Nile (+$400)",
+	}
+	if sent := client.take(); strings.Join(sent, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected private answers: %q", sent)
+	}
+}
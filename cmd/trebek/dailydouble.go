@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"trebek/internal/game"
+	"trebek/internal/question"
+)
+
+// announceDailyDouble posts a Daily Double. The clue stays hidden until a
+// player claims it with the dd command, which commandPrefix introduces.
+func announceDailyDouble(ircClient messenger, triviaGame *game.Game, q *question.Question, dd game.DailyDouble, prefix, commandPrefix string) {
+	channel := triviaGame.GameChannel
+	if dd.Player != "" {
+		// Re-asked after a reconnect
+		ircClient.Privmsg(channel, fmt.Sprintf("%sDaily Double in %s for %s, who wagered %s - Question: %s", prefix, q.Category, dd.Player, game.FormatDollars(dd.Wager), q.Question))
+		return
+	}
+	ircClient.Privmsg(channel, fmt.Sprintf("%sDaily Double in %s! The first to claim it with %sdd <wager> answers alone, wagering up to %s or their score.",
+		prefix, q.Category, commandPrefix, game.FormatDollars(triviaGame.ClueValue(q))))
+}
+
+// answerDailyDouble judges the one response the player who claimed the Daily
+// Double gets. A correct response wins the wager and a wrong one loses it;
+// either way the clue is closed. If time runs out first, the wager is lost too.
+func answerDailyDouble(ircClient messenger, triviaGame *game.Game, dd game.DailyDouble, answerAttempt string) {
+	q, correct := triviaGame.ClaimAnswer(dd.Player, answerAttempt)
+	if q == nil {
+		return // Time ran out first
+	}
+	if correct {
+		ircClient.Privmsg(triviaGame.GameChannel, fmt.Sprintf("Correct, %s! The answer was: %s (+%s)", dd.Player, q.Answer, game.FormatDollars(dd.Wager)))
+		triviaGame.AwardCorrectFor(dd.Player, dd.Wager, game.ReasonDailyDouble, q)
+	} else {
+		if _, closed := triviaGame.CloseQuestion(q); !closed {
+			return // Time ran out first
+		}
+		ircClient.Privmsg(triviaGame.GameChannel, fmt.Sprintf("Sorry, %s, that's not correct. The answer was: %s (-%s)", dd.Player, q.Answer, game.FormatDollars(dd.Wager)))
+		triviaGame.ChargeFor(dd.Player, dd.Wager, game.ReasonDailyDouble, q)
+	}
+	if triviaGame.GetPlaying() {
+		triviaGame.AnswerGiven <- true // Signal that an answer was given
+	}
+}
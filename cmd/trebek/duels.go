@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"trebek/internal/game"
+)
+
+// askDuelQuestion asks the next question of the duel under way, or ends the
+// duel once it is decided. The duelists get the duel window to answer.
+func askDuelQuestion(ircClient messenger, triviaGame *game.Game) {
+	d := triviaGame.CurrentDuel()
+	if d == nil || !d.Accepted {
+		return // The duel was stopped
+	}
+	if d.Done() {
+		finishDuel(ircClient, triviaGame)
+		return
+	}
+	channel := triviaGame.GameChannel
+	q := triviaGame.StartRound()
+	if q == nil {
+		ircClient.Privmsg(channel, "No more questions left! Reset the game or load more questions.")
+		finishDuel(ircClient, triviaGame)
+		return
+	}
+	ircClient.Privmsg(channel, fmt.Sprintf("Duel question %d of %d (%s) - Category: %s - Question: %s", d.Asked+1, d.BestOf, d.Score(), q.Category, q.Question))
+
+	triviaGame.QuestionTimer = time.AfterFunc(triviaGame.Settings().DuelWindow, func() {
+		if _, closed := triviaGame.CloseQuestion(q); !closed {
+			return // Answered or stopped
+		}
+		ircClient.Privmsg(channel, fmt.Sprintf("Time's up! The answer was: %s", q.Answer))
+		nextDuelQuestion(ircClient, triviaGame)
+	})
+}
+
+// nextDuelQuestion ends the duel if it is decided, or asks the next question
+// after the answer delay.
+func nextDuelQuestion(ircClient messenger, triviaGame *game.Game) {
+	if d := triviaGame.CurrentDuel(); d != nil && d.Done() {
+		finishDuel(ircClient, triviaGame)
+		return
+	}
+	time.AfterFunc(triviaGame.Settings().AnswerDelay, func() {
+		askDuelQuestion(ircClient, triviaGame)
+	})
+}
+
+// answerDuel judges a duelist's answer. The first correct one takes the
+// question, unless its time ran out first.
+func answerDuel(ircClient messenger, triviaGame *game.Game, user, target, answerAttempt string) {
+	q, correct := triviaGame.ClaimAnswer(user, answerAttempt)
+	if q == nil {
+		return // Time ran out first
+	}
+	if !correct {
+		ircClient.Privmsg(target, fmt.Sprintf("Sorry, %s, that's not correct.", user))
+		return
+	}
+	d := triviaGame.WinDuelQuestion(user)
+	if d == nil {
+		return
+	}
+	ircClient.Privmsg(triviaGame.GameChannel, fmt.Sprintf("Correct, %s! The answer was: %s (%s)", user, q.Answer, d.Score()))
+	nextDuelQuestion(ircClient, triviaGame)
+}
+
+// finishDuel ends the duel under way, announces the result and updates the
+// duelists' head-to-head record.
+func finishDuel(ircClient messenger, triviaGame *game.Game) {
+	d := triviaGame.EndDuel(true)
+	if d == nil {
+		return
+	}
+	channel := triviaGame.GameChannel
+	if winner := d.Winner(); winner != "" {
+		loser := d.Opponent
+		if winner == d.Opponent {
+			loser = d.Challenger
+		}
+		ircClient.Privmsg(channel, fmt.Sprintf("%s wins the duel against %s, %d-%d!", winner, loser, d.Wins[winner], d.Wins[loser]))
+	} else {
+		ircClient.Privmsg(channel, fmt.Sprintf("The duel between %s and %s ends in a %d-%d draw.", d.Challenger, d.Opponent, d.Wins[d.Challenger], d.Wins[d.Opponent]))
+	}
+	record := triviaGame.HeadToHead(d.Challenger)[d.Opponent]
+	ircClient.Privmsg(channel, fmt.Sprintf("Head to head, %s is now %s against %s.", d.Challenger, record, d.Opponent))
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"trebek/internal/commands"
+	"trebek/internal/game"
+)
+
+// finalRevealPause is the pause between revealing finalists' responses.
+var finalRevealPause = 3 * time.Second
+
+// startFinal begins Final Jeopardy for the match in progress and schedules
+// its wager and response deadlines. It returns false if the round cannot be
+// played, e.g. because nobody has money to wager.
+func startFinal(ircClient messenger, triviaGame *game.Game) bool {
+	channel := triviaGame.GameChannel
+	final, err := triviaGame.StartFinal()
+	if err != nil {
+		ircClient.Privmsg(channel, fmt.Sprintf("No Final Jeopardy this time: %v.", err))
+		return false
+	}
+	window := triviaGame.Settings().FinalTime
+
+	var finalists []string
+	for _, p := range game.RankScores(final.Scores) {
+		finalists = append(finalists, fmt.Sprintf("%s (%s)", p.Name, game.FormatDollars(p.Score)))
+	}
+	ircClient.Privmsg(channel, fmt.Sprintf("It's time for Final Jeopardy! The category is: %s", final.Question.Category))
+	ircClient.Privmsg(channel, fmt.Sprintf("Finalists %s: send me your wager privately, e.g. \"wager 500\", within %s.", strings.Join(finalists, ", "), window))
+
+	time.AfterFunc(window, func() {
+		q := triviaGame.CloseWagers()
+		if q == nil {
+			return // The match was stopped
+		}
+		ircClient.Privmsg(channel, fmt.Sprintf("Wagers are in. The Final Jeopardy clue: %s", q.Question))
+		ircClient.Privmsg(channel, fmt.Sprintf("Finalists, send me your response privately within %s.", window))
+		time.AfterFunc(window, func() {
+			revealFinal(ircClient, triviaGame)
+		})
+	})
+	return true
+}
+
+// parseWager reads a dollar amount such as "1500" or "$1,500".
+func parseWager(s string) (int, error) {
+	return strconv.Atoi(strings.NewReplacer("$", "", ",", "").Replace(strings.TrimSpace(s)))
+}
+
+// finalInput returns the wager or response in a finalist's private message,
+// or false if the message is a command to run instead. A response may be sent
+// with the answer command too.
+func finalInput(registry *commands.Registry, message string) (string, bool) {
+	input := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message), registry.Prefix))
+	name, rest, _ := strings.Cut(input, " ")
+	cmd := registry.Lookup(name)
+	switch {
+	case cmd == nil:
+		return input, true
+	case cmd.Name == "answer":
+		return strings.TrimSpace(rest), true
+	}
+	return "", false
+}
+
+// handleFinalMessage takes a finalist's private wager or response.
+func handleFinalMessage(ircClient messenger, triviaGame *game.Game, user, message string) {
+	final := triviaGame.CurrentFinal()
+	if final == nil {
+		return
+	}
+	switch final.Phase {
+	case game.FinalWagering:
+		wager, err := parseWager(strings.TrimPrefix(strings.ToLower(message), "wager"))
+		if err != nil {
+			ircClient.Privmsg(user, "Send your wager as a number, e.g. \"wager 500\".")
+			return
+		}
+		if err := triviaGame.PlaceWager(user, wager); err != nil {
+			ircClient.Privmsg(user, fmt.Sprintf("Sorry, %v.", err))
+			return
+		}
+		ircClient.Privmsg(user, fmt.Sprintf("Your wager of %s is in. You can change it until wagers close.", game.FormatDollars(wager)))
+	case game.FinalAnswering:
+		if err := triviaGame.SubmitFinalAnswer(user, message); err != nil {
+			ircClient.Privmsg(user, fmt.Sprintf("Sorry, %v.", err))
+			return
+		}
+		ircClient.Privmsg(user, "Got it. You can change your response until time runs out.")
+	}
+}
+
+// revealFinal closes Final Jeopardy, reveals each finalist's response and
+// wager in turn, and ends the match.
+func revealFinal(ircClient messenger, triviaGame *game.Game) {
+	final := triviaGame.CurrentFinal()
+	results := triviaGame.CloseFinal()
+	if final == nil || results == nil {
+		return // The match was stopped
+	}
+	channel := triviaGame.GameChannel
+	ircClient.Privmsg(channel, "Time's up! Let's see what our finalists wrote.")
+	for _, r := range results {
+		time.Sleep(finalRevealPause)
+		answer := "nothing"
+		if r.Answer != "" {
+			answer = fmt.Sprintf("%q", r.Answer)
+		}
+		verdict := "incorrect"
+		if r.Correct {
+			verdict = "correct"
+		}
+		ircClient.Privmsg(channel, fmt.Sprintf("%s wrote %s: %s! Wager: %s, score: %s -> %s",
+			r.Player, answer, verdict, game.FormatDollars(r.Wager), game.FormatDollars(r.Before), game.FormatDollars(r.After)))
+	}
+	time.Sleep(finalRevealPause)
+	ircClient.Privmsg(channel, fmt.Sprintf("The correct response was: %s", final.Question.Answer))
+	finishMatch(ircClient, triviaGame)
+}
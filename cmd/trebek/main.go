@@ -10,6 +10,8 @@ import (
 	"syscall"
 	"time"
 
+	"trebek/internal/auth"
+	"trebek/internal/commands"
 	"trebek/internal/config"
 	"trebek/internal/game"
 	"trebek/internal/irc"
@@ -42,8 +44,65 @@ func main() {
 IRC_SERVER=localhost:6667
 IRC_SERVER_TLS=localhost:6697
 IRC_CHANNEL=#
+# Each channel runs its own game and scoreboard
+# IRC_CHANNEL=#trivia,#trivia-hard
 # LOG_FILE_PATH=/path/to/your/logfile.log
-# LOG_LEVEL=info # debug, info, warn, error
+# Log level: debug, info, warn or error
+# LOG_LEVEL=info
+# SASL mechanism: PLAIN or EXTERNAL
+# SASL_MECHANISM=PLAIN
+# SASL_USERNAME=
+# SASL_PASSWORD=
+# For SASL EXTERNAL
+# TLS_CLIENT_CERT=/path/to/client.crt
+# TLS_CLIENT_KEY=/path/to/client.key
+# Tried when BOT_NAME is taken, then BOT_NAME_
+# ALT_NICKS=TrebekBot2,AlexBot
+# NICKSERV_PASSWORD=
+# Lines sent back to back before rate limiting
+# FLOOD_BURST=5
+# Lines per second after the burst
+# FLOOD_RATE=0.5
+# One or more characters; "TrebekBot: help" and private messages always work
+# COMMAND_PREFIX=!
+# Comma-separated account:name, nick!user@host globs or channel status (~ & @ % +)
+# OWNERS=account:yournick
+# May reset the scoreboard; channel operators by default
+# ADMINS=@
+# May stop the game; channel half-operators by default
+# MODERATORS=%
+# Game settings take a default and optional per-channel overrides; admins can change them with !set
+# How closely answers must match: exact, strict, normal or lenient
+# ANSWER_STRICTNESS=normal,#trivia-hard=strict
+# Time to answer a question
+# QUESTION_TIMEOUT=30s
+# Time from one question to the next in continuous play
+# QUESTION_INTERVAL=45s
+# Pause after an answer before the next question
+# ANSWER_DELAY=5s
+# MAX_HINTS=3
+# Votes needed to skip a question
+# SKIP_VOTES=3
+# Dollars for clues without a value, such as Final Jeopardy
+# DEFAULT_CLUE_VALUE=1000
+# Percent of the clue's value each hint costs
+# HINT_PENALTY=10
+# Questions between standings during a !match; 0 for none
+# STANDINGS_EVERY=5
+# End every !match with a Final Jeopardy wagering round
+# FINAL_JEOPARDY=off
+# Time to wager, and then to respond, in Final Jeopardy
+# FINAL_TIME=30s
+# Percent of clues in continuous play that are Daily Doubles; 0 for none
+# DAILY_DOUBLE_CHANCE=5
+# Correct answers also count for the player's !team
+# TEAM_MODE=off
+# In team mode, time other teams get to answer after the first correct answer; only each team's first counts
+# TEAM_WINDOW=0s
+# Time duelists get to buzz in with an answer to each !duel question
+# DUEL_WINDOW=20s
+# Periods whose winners are announced when they end: day, week, month, year or off
+# ANNOUNCE_WINNERS=week month
 `
 		err := os.WriteFile(configFilePath, []byte(defaultConfigContent), 0600)
 		if err != nil {
@@ -97,16 +156,29 @@ IRC_CHANNEL=#
 	}))
 	slog.SetDefault(logger)
 
-	// Load questions
-	questionSource, err := question.NewJSONQuestionSource()
-	if err != nil {
-		slog.Error("Failed to create question source", "error", err)
-		os.Exit(1)
+	// Initialize one game per channel, each with its own question stream, scoreboard and timers
+	channels := cfg.Channels()
+	games := make(map[string]*game.Game, len(channels))
+	for _, channel := range channels {
+		questionSource, err := question.NewJSONQuestionSource()
+		if err != nil {
+			slog.Error("Failed to create question source", "channel", channel, "error", err)
+			os.Exit(1)
+		}
+		defer questionSource.Close() // Ensure the question source is closed
+		triviaGame := game.NewGame(questionSource, channel)
+		for _, key := range config.GameSettingKeys {
+			value := config.ForChannel(cfg.GameSettings[key], channel)
+			if value == "" {
+				continue
+			}
+			if err := triviaGame.Configure(strings.ToLower(key), value); err != nil {
+				slog.Error("Invalid game setting", "key", key, "channel", channel, "error", err)
+				os.Exit(1)
+			}
+		}
+		games[strings.ToLower(channel)] = triviaGame
 	}
-	defer questionSource.Close() // Ensure the question source is closed
-
-	// Initialize game
-	triviaGame := game.NewGame(questionSource, cfg.IRCChannel)
 
 	// Create IRC client
 	ircClient := irc.NewClient(cfg)
@@ -114,112 +186,77 @@ IRC_CHANNEL=#
 	// Game loop control
 	gameStopChan := make(chan struct{})
 
+	// Register commands, with privileged ones checked against the configured roles
+	authorizer, err := auth.NewAuthorizer(map[auth.Role]string{
+		auth.Owner:     cfg.Owners,
+		auth.Admin:     cfg.Admins,
+		auth.Moderator: cfg.Moderators,
+	})
+	if err != nil {
+		slog.Error("Invalid role configuration", "error", err)
+		os.Exit(1)
+	}
+	registry := commands.NewRegistry(cfg.CommandPrefix)
+	registry.Authorize = func(ctx *commands.Context, perm commands.Permission) bool {
+		user, _ := ircClient.LookupUser(ctx.User)
+		return authorizer.Allowed(auth.Identity{
+			Nick:     ctx.User,
+			Hostmask: user.Hostmask(),
+			Account:  user.Account,
+			Status:   ircClient.ChannelStatus(ctx.Channel, ctx.User),
+		}, perm)
+	}
+	(&trivia{client: ircClient, games: games, stopChan: gameStopChan}).registerCommands(registry)
+
 	// Set up message handler
-	ircClient.Handler = func(target, user, message string) {
+	ircClient.Handler = func(msg *irc.Message) {
+		if msg.Command != "PRIVMSG" || len(msg.Params) < 2 {
+			return
+		}
+		target := msg.Param(0)
+		user := msg.Nick()
+		message := strings.TrimSpace(msg.Trailing())
+		if ircClient.IsMe(user) {
+			return // Never react to our own messages, whatever nick we ended up with
+		}
+
 		slog.Info("Message received", "user", user, "target", target, "message", message)
-		msgLower := strings.ToLower(message)
 
-		// Handle commands first
-		if strings.HasPrefix(message, "!") {
-			switch {
-			case strings.HasPrefix(msgLower, "!hello"):
-				ircClient.Privmsg(target, fmt.Sprintf("Hello, %s!", user))
-			case strings.HasPrefix(msgLower, "!start"):
-				if triviaGame.GetPlaying() {
-					ircClient.Privmsg(target, "Trivia is already running!")
-					return
-				}
-				triviaGame.SetPlaying(true)
-				ircClient.Privmsg(target, "Starting continuous trivia!")
-				go gameLoop(ircClient, triviaGame, gameStopChan)
-				askQuestion(ircClient, triviaGame) // Ask the first question immediately
-			case strings.HasPrefix(msgLower, "!stop"):
-				if !triviaGame.GetPlaying() {
-					ircClient.Privmsg(target, "Trivia is not currently running.")
-					return
-				}
-				triviaGame.SetPlaying(false)
-				ircClient.Privmsg(target, "Stopping continuous trivia.")
-			case strings.HasPrefix(msgLower, "!question"):
-				if triviaGame.GetPlaying() {
-					ircClient.Privmsg(target, "Trivia is running continuously. Please use !stop to end continuous play if you want to ask questions manually.")
-					return
-				}
-				askQuestion(ircClient, triviaGame)
-			case strings.HasPrefix(msgLower, "!answer "): // Keep !answer for explicit answers
-				if triviaGame.GetCurrentQuestion() == nil {
-					ircClient.Privmsg(target, "No question is currently active. Type !question to get one.")
-					return
-				}
-				answerAttempt := strings.TrimPrefix(message, "!answer ")
-				handleAnswer(ircClient, triviaGame, user, target, answerAttempt)
-			case strings.HasPrefix(msgLower, "!hint"):
-				hint, given := triviaGame.GetHint()
-				if given {
-					ircClient.Privmsg(target, fmt.Sprintf("Hint for %s: %s", triviaGame.GetCurrentQuestion().Category, hint))
-					triviaGame.Scoreboard.AddScore(user, -game.HintCost) // Subtract points for hint
-				} else {
-					ircClient.Privmsg(target, hint) // Error message from GetHint
-				}
-			case strings.HasPrefix(msgLower, "!score"):
-				score := triviaGame.Scoreboard.GetScore(user)
-				ircClient.Privmsg(target, fmt.Sprintf("%s's score: %d", user, score))
-			case strings.HasPrefix(msgLower, "!topscores"):
-				scores := triviaGame.Scoreboard.Scores
-				if len(scores) == 0 {
-					ircClient.Privmsg(target, "No scores yet!")
-					return
-				}
-				// Sort scores (simple bubble sort for few entries, or use sort.Slice)
-				type player struct {
-					name  string
-					score int
-				}
-				var players []player
-				for name, s := range scores {
-					players = append(players, player{name, s})
-				}
-				for i := 0; i < len(players); i++ {
-					for j := i + 1; j < len(players); j++ {
-						if players[i].score < players[j].score {
-							players[i], players[j] = players[j], players[i]
-						}
-					}
-				}
-				response := "Top Scores: "
-				for i, p := range players {
-					if i >= 5 { // Top 5
-						break
+		// Route by target. Private messages use the game of a channel the user is
+		// on and are answered privately; results are still announced in the channel.
+		channel := target
+		private := ircClient.IsMe(target)
+		if private {
+			// Final Jeopardy wagers and responses are sent privately
+			if input, ok := finalInput(registry, message); ok {
+				for _, triviaGame := range games {
+					if triviaGame.IsFinalist(user) {
+						handleFinalMessage(ircClient, triviaGame, user, input)
+						return
 					}
-					response += fmt.Sprintf("%s: %d ", p.name, p.score)
-				}
-				ircClient.Privmsg(target, response)
-			case strings.HasPrefix(msgLower, "!resetscoreboard"):
-				triviaGame.Scoreboard.Reset()
-				ircClient.Privmsg(target, "Scoreboard has been reset!")
-			case strings.HasPrefix(msgLower, "!skip"):
-				if triviaGame.GetCurrentQuestion() == nil {
-					ircClient.Privmsg(target, "No question is currently active to skip.")
-					return
-				}
-				currentVotes, threshold, skipped := triviaGame.AddNextVote(user)
-				if skipped {
-					ircClient.Privmsg(target, fmt.Sprintf("Question skipped! The answer was: %s", triviaGame.GetCurrentQuestion().Answer))
-					triviaGame.ClearCurrentQuestion()
-					if triviaGame.GetPlaying() {
-						triviaGame.AnswerGiven <- false // Signal to game loop to get next question
-					}
-				} else {
-					ircClient.Privmsg(target, fmt.Sprintf("%s voted to skip. %d/%d votes to skip.", user, currentVotes, threshold))
 				}
-			case strings.HasPrefix(msgLower, "!help"):
-				ircClient.Privmsg(target, "Commands: !start, !stop, !question, !answer <your answer>, !hint, !score, !topscores, !resetscoreboard, !skip, !help")
-			default:
-				// Unknown command
-				ircClient.Privmsg(target, fmt.Sprintf("Unknown command: %s. Type !help for commands.", message))
 			}
-		} else if triviaGame.GetPlaying() && triviaGame.GetCurrentQuestion() != nil {
-			// If in continuous play and a question is active, treat non-command messages as answers
+			channel, target = privateChannel(channels, user, ircClient.OnChannel), user
+		}
+		triviaGame, ok := games[strings.ToLower(channel)]
+		if !ok {
+			return // Not one of our game channels
+		}
+
+		// Handle commands first
+		if input, ok := registry.Parse(message, ircClient.Nick(), private); ok {
+			ctx := &commands.Context{
+				User:    user,
+				Target:  target,
+				Channel: channel,
+				Private: private,
+				Reply:   func(reply string) { ircClient.Privmsg(target, reply) },
+			}
+			if !registry.Dispatch(ctx, input) {
+				ircClient.Privmsg(target, fmt.Sprintf("Unknown command: %s. Type %shelp for commands.", message, registry.Prefix))
+			}
+		} else if !private && (triviaGame.GetPlaying() || triviaGame.Dueling()) && triviaGame.GetCurrentQuestion() != nil {
+			// If in continuous play or a duel and a question is active, treat non-command messages as answers
 			handleAnswer(ircClient, triviaGame, user, target, message)
 		}
 	}
@@ -237,67 +274,237 @@ IRC_CHANNEL=#
 		os.Exit(1)
 	}
 
-	err = ircClient.Connect(useTLS)
-	if err != nil {
-		slog.Error("Failed to connect to IRC", "error", err)
-		os.Exit(1)
+	// Pause the game while disconnected and pick up where it left off afterwards
+	ircClient.OnDisconnect = func(err error) {
+		slog.Warn("Lost connection to IRC, pausing games", "error", err)
+		for _, triviaGame := range games {
+			triviaGame.Pause()
+		}
+	}
+	ircClient.OnConnect = func() {
+		for _, triviaGame := range games {
+			if !triviaGame.IsPaused() {
+				continue
+			}
+			triviaGame.Resume()
+			if q := triviaGame.GetCurrentQuestion(); q != nil {
+				ircClient.Privmsg(triviaGame.GameChannel, "Sorry about that, we're back!")
+				announceQuestion(ircClient, triviaGame, q, registry.Prefix)
+			}
+		}
+	}
+
+	// Announce the winners of each day, week, month or year as it ends
+	for _, triviaGame := range games {
+		go watchPeriods(ircClient, triviaGame)
 	}
-	defer ircClient.Close()
 
-	// Join channel after a short delay to allow for server registration
-	time.Sleep(2 * time.Second)
-	ircClient.JoinChannel(cfg.IRCChannel)
+	// Channels are joined as soon as the server welcomes us and are rejoined after reconnects
+	for _, channel := range channels {
+		ircClient.JoinChannel(channel)
+	}
 
 	// Handle graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	go ircClient.Listen() // Start listening in a goroutine
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- ircClient.Run(useTLS)
+	}()
 
 	slog.Info("Trebek bot started. Waiting for messages...")
-	<-sigChan // Block until a signal is received
-	slog.Info("Shutting down bot...")
+	select {
+	case <-sigChan: // Block until a signal is received
+		slog.Info("Shutting down bot...")
+		ircClient.Quit("Shutting down")
+	case err := <-runErr:
+		if err != nil {
+			slog.Error("IRC client stopped", "error", err)
+			os.Exit(1)
+		}
+	}
 }
 
-func askQuestion(ircClient *irc.Client, triviaGame *game.Game) {
+// askQuestion asks the next question. commandPrefix is the prefix commands
+// are given with, for announcements that name one.
+func askQuestion(ircClient messenger, triviaGame *game.Game, commandPrefix string) {
+	if m := triviaGame.CurrentMatch(); m != nil {
+		if triviaGame.CurrentFinal() != nil {
+			return // Final Jeopardy is under way and ends the match itself
+		}
+		if m.Done() {
+			if m.Final && startFinal(ircClient, triviaGame) {
+				return
+			}
+			finishMatch(ircClient, triviaGame)
+			return
+		}
+		if every := triviaGame.Settings().StandingsEvery; every > 0 && m.Asked > 0 && m.Asked%every == 0 {
+			ircClient.Privmsg(triviaGame.GameChannel, fmt.Sprintf("Standings after %d of %d: %s", m.Asked, m.Questions, formatStandings(m.Standings())))
+		}
+	}
 	q := triviaGame.StartRound()
 	if q == nil {
 		ircClient.Privmsg(triviaGame.GameChannel, "No more questions left! Reset the game or load more questions.")
 		triviaGame.SetPlaying(false) // Stop continuous play if no questions left
+		finishMatch(ircClient, triviaGame)
 		return
 	}
-	ircClient.Privmsg(triviaGame.GameChannel, fmt.Sprintf("Category: %s - Question: %s", q.Category, q.Question))
+	announceQuestion(ircClient, triviaGame, q, commandPrefix)
+}
 
-	// Start a timer for the question
-	triviaGame.QuestionTimer = time.AfterFunc(30*time.Second, func() {
-		if triviaGame.GetCurrentQuestion() != nil { // If still unanswered
-			ircClient.Privmsg(triviaGame.GameChannel, fmt.Sprintf("Time's up! The answer was: %s", triviaGame.GetCurrentQuestion().Answer))
-			triviaGame.ClearCurrentQuestion()
-			if triviaGame.GetPlaying() {
-				triviaGame.AnswerGiven <- false // Signal that time ran out
-			}
+// privateChannel picks the game channel a private message from user applies
+// to: the first one the user is on, or the first one if they are on none.
+func privateChannel(channels []string, user string, onChannel func(channel, nick string) bool) string {
+	for _, channel := range channels {
+		if onChannel(channel, user) {
+			return channel
 		}
-	})
+	}
+	return channels[0]
+}
+
+// announceQuestion posts the question to the game channel and starts its timer.
+func announceQuestion(ircClient messenger, triviaGame *game.Game, q *question.Question, commandPrefix string) {
+	prefix := ""
+	if m := triviaGame.CurrentMatch(); m != nil {
+		prefix = fmt.Sprintf("Question %d of %d - ", m.Asked, m.Questions)
+	}
+	if dd, ok := triviaGame.CurrentDailyDouble(); ok {
+		announceDailyDouble(ircClient, triviaGame, q, dd, prefix, commandPrefix)
+	} else {
+		ircClient.Privmsg(triviaGame.GameChannel, fmt.Sprintf("%sCategory: %s for %s - Question: %s", prefix, q.Category, game.FormatDollars(triviaGame.ClueValue(q)), q.Question))
+	}
+	startQuestionTimer(ircClient, triviaGame)
 }
 
-func handleAnswer(ircClient *irc.Client, triviaGame *game.Game, user, target, answerAttempt string) {
-	if triviaGame.CheckAnswer(answerAttempt) {
-		ircClient.Privmsg(target, fmt.Sprintf("Correct, %s! The answer was: %s", user, triviaGame.GetCurrentQuestion().Answer))
-		triviaGame.Scoreboard.AddScore(user, 1) // Award 1 point for correct answer
-		triviaGame.ClearCurrentQuestion()
+// startQuestionTimer gives players a fresh window to answer the current
+// question, replacing any timer already running.
+func startQuestionTimer(ircClient messenger, triviaGame *game.Game) {
+	if triviaGame.QuestionTimer != nil {
+		triviaGame.QuestionTimer.Stop()
+	}
+	q := triviaGame.GetCurrentQuestion()
+	triviaGame.QuestionTimer = time.AfterFunc(triviaGame.Settings().QuestionTimeout, func() {
+		dd, closed := triviaGame.CloseQuestion(q)
+		if !closed {
+			return // Already answered
+		}
+		if dd.Player != "" {
+			// Not responding to a Daily Double costs the wager
+			triviaGame.ChargeFor(dd.Player, dd.Wager, game.ReasonDailyDouble, q)
+			ircClient.Privmsg(triviaGame.GameChannel, fmt.Sprintf("Time's up, %s! The answer was: %s (-%s)", dd.Player, q.Answer, game.FormatDollars(dd.Wager)))
+		} else {
+			ircClient.Privmsg(triviaGame.GameChannel, fmt.Sprintf("Time's up! The answer was: %s", q.Answer))
+		}
 		if triviaGame.GetPlaying() {
-			triviaGame.AnswerGiven <- true // Signal that an answer was given
+			triviaGame.AnswerGiven <- false // Signal that time ran out
 		}
+	})
+}
+
+// finishMatch ends the match in progress, if any, and announces the results.
+func finishMatch(ircClient messenger, triviaGame *game.Game) {
+	m := triviaGame.EndMatch()
+	if m == nil {
+		return
+	}
+	triviaGame.SetPlaying(false)
+	channel := triviaGame.GameChannel
+	if m.Completed {
+		ircClient.Privmsg(channel, fmt.Sprintf("Match over after %s!", plural(m.Questions, "question")))
 	} else {
+		ircClient.Privmsg(channel, fmt.Sprintf("Match stopped after %d of %d questions.", m.Asked, m.Questions))
+	}
+
+	standings := m.Standings()
+	if len(standings) == 0 {
+		ircClient.Privmsg(channel, "Nobody scored this match.")
+		return
+	}
+	winners := []string{standings[0].Name}
+	for _, p := range standings[1:] {
+		if p.Score == standings[0].Score {
+			winners = append(winners, p.Name)
+		}
+	}
+	if len(winners) > 1 {
+		ircClient.Privmsg(channel, fmt.Sprintf("It's a tie between %s with %s each!", strings.Join(winners, " and "), game.FormatDollars(standings[0].Score)))
+	} else {
+		ircClient.Privmsg(channel, fmt.Sprintf("Winner: %s with %s (%d correct)", winners[0], game.FormatDollars(standings[0].Score), m.Correct[winners[0]]))
+		if len(standings) > 1 {
+			runnerUp := standings[1]
+			ircClient.Privmsg(channel, fmt.Sprintf("Runner-up: %s with %s (%d correct)", runnerUp.Name, game.FormatDollars(runnerUp.Score), m.Correct[runnerUp.Name]))
+		}
+	}
+	ircClient.Privmsg(channel, fmt.Sprintf("%d of %s answered by %s in %s.",
+		m.Answered(), plural(m.Asked, "question"), plural(len(standings), "player"), m.EndedAt.Sub(m.StartedAt).Round(time.Second)))
+}
+
+// plural formats a count with a singular or plural noun, e.g. "1 question".
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// formatStandings lists players and their scores from best to worst.
+func formatStandings(standings []game.PlayerScore) string {
+	if len(standings) == 0 {
+		return "no scores yet"
+	}
+	parts := make([]string, len(standings))
+	for i, p := range standings {
+		parts[i] = fmt.Sprintf("%d. %s %s", i+1, p.Name, game.FormatDollars(p.Score))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func handleAnswer(ircClient messenger, triviaGame *game.Game, user, target, answerAttempt string) {
+	if d := triviaGame.CurrentDuel(); d != nil && d.Accepted {
+		if d.Includes(user) { // Only the duelists' answers count
+			answerDuel(ircClient, triviaGame, user, target, answerAttempt)
+		}
+		return
+	}
+	if dd, ok := triviaGame.CurrentDailyDouble(); ok {
+		if dd.Player == user { // Everyone else sits a Daily Double out
+			answerDailyDouble(ircClient, triviaGame, dd, answerAttempt)
+		}
+		return
+	}
+	if settings := triviaGame.Settings(); settings.TeamMode && settings.TeamWindow > 0 {
+		// The question stays open for the other teams
+		if triviaGame.Attempt(user, answerAttempt) {
+			answerTeamWindow(ircClient, triviaGame, user, target, settings.TeamWindow)
+		} else {
+			ircClient.Privmsg(target, fmt.Sprintf("Sorry, %s, that's not correct.", user))
+		}
+		return
+	}
+	q, correct := triviaGame.ClaimAnswer(user, answerAttempt)
+	if q == nil {
+		return // Time ran out or someone else got it first
+	}
+	if !correct {
 		ircClient.Privmsg(target, fmt.Sprintf("Sorry, %s, that's not correct.", user))
+		return
+	}
+	value := triviaGame.ClueValue(q)
+	ircClient.Privmsg(triviaGame.GameChannel, fmt.Sprintf("Correct, %s! The answer was: %s (+%s)", user, q.Answer, game.FormatDollars(value)))
+	triviaGame.AwardCorrectFor(user, value, game.ReasonCorrect, q) // Award the clue's dollar value
+	if triviaGame.GetPlaying() {
+		triviaGame.AnswerGiven <- true // Signal that an answer was given
 	}
 }
 
-func gameLoop(ircClient *irc.Client, triviaGame *game.Game, stopChan <-chan struct{}) {
+func gameLoop(ircClient messenger, triviaGame *game.Game, commandPrefix string, stopChan <-chan struct{}) {
 	// Initial delay before asking the next question after an answer or skip
 	// This ensures there's a brief pause before the next question appears.
-	nextQuestionDelay := 5 * time.Second
-	nextQuestionTimer := time.NewTimer(nextQuestionDelay)
+	// Settings are read on every use so changes made with !set apply right away.
+	nextQuestionTimer := time.NewTimer(triviaGame.Settings().AnswerDelay)
 	defer nextQuestionTimer.Stop()
 
 	// Stop the initial timer immediately, as we'll manage it manually
@@ -307,20 +514,30 @@ func gameLoop(ircClient *irc.Client, triviaGame *game.Game, stopChan <-chan stru
 		select {
 		case <-nextQuestionTimer.C:
 			// Time for the next question
+			if triviaGame.IsPaused() {
+				// Disconnected from IRC; check again shortly
+				nextQuestionTimer.Reset(triviaGame.Settings().AnswerDelay)
+				continue
+			}
+			if triviaGame.GetCurrentQuestion() != nil {
+				// A question re-asked after a reconnect is still open; wait for it
+				nextQuestionTimer.Reset(triviaGame.Settings().QuestionInterval)
+				continue
+			}
 			if triviaGame.GetPlaying() { // Double check playing state
-				askQuestion(ircClient, triviaGame)
+				askQuestion(ircClient, triviaGame, commandPrefix)
 				// Reset timer for the next question after this one is asked
-				nextQuestionTimer.Reset(45 * time.Second) // Default interval for next question
+				nextQuestionTimer.Reset(triviaGame.Settings().QuestionInterval)
 			}
 		case answered := <-triviaGame.AnswerGiven:
 			// An answer was given or question timed out/skipped
 			if triviaGame.GetPlaying() { // Double check playing state
 				if answered {
 					// If answered correctly, ask next question after a short delay
-					nextQuestionTimer.Reset(nextQuestionDelay)
+					nextQuestionTimer.Reset(triviaGame.Settings().AnswerDelay)
 				} else {
 					// If timed out or skipped, ask next question after a short delay
-					nextQuestionTimer.Reset(nextQuestionDelay)
+					nextQuestionTimer.Reset(triviaGame.Settings().AnswerDelay)
 				}
 			}
 		case <-stopChan:
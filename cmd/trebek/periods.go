@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"trebek/internal/game"
+)
+
+// periodCheckInterval is how often the bot checks whether a leaderboard
+// period has ended.
+const periodCheckInterval = time.Minute
+
+// watchPeriods announces the winners of each period as it ends, for as long
+// as the bot runs.
+func watchPeriods(ircClient messenger, triviaGame *game.Game) {
+	last := time.Now()
+	ticker := time.NewTicker(periodCheckInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		announcePeriodWinners(ircClient, triviaGame, last, now)
+		last = now
+	}
+}
+
+// announcePeriodWinners announces the winners of the periods, of the kinds
+// chosen in the settings, that ended between before and now.
+func announcePeriodWinners(ircClient messenger, triviaGame *game.Game, before, now time.Time) {
+	for _, kind := range triviaGame.Settings().WinnerPeriods() {
+		label := game.PeriodLabel(kind, before)
+		if label == game.PeriodLabel(kind, now) {
+			continue // Still under way
+		}
+		standings := game.RankScores(triviaGame.Scoreboard.PeriodScores(label))
+		if len(standings) == 0 || standings[0].Score <= 0 {
+			continue // Nobody scored
+		}
+		var others []string
+		for _, p := range standings[1:min(len(standings), 3)] {
+			others = append(others, fmt.Sprintf("%s (%s)", p.Name, game.FormatDollars(p.Score)))
+		}
+		message := fmt.Sprintf("That's the end of %s %s! The winner is %s with %s", kind, label, standings[0].Name, game.FormatDollars(standings[0].Score))
+		if len(others) > 0 {
+			message += ", ahead of " + strings.Join(others, " and ")
+		}
+		ircClient.Privmsg(triviaGame.GameChannel, message+".")
+	}
+}
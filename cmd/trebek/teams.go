@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"trebek/internal/game"
+)
+
+// answerTeamWindow credits a correct answer while other teams may still
+// answer. The first correct answer opens a window of the given length, after
+// which the question closes; within it, each team's first correct answer
+// counts.
+func answerTeamWindow(ircClient messenger, triviaGame *game.Game, user, target string, window time.Duration) {
+	q, side, first, err := triviaGame.TeamAnswer(user)
+	if err != nil {
+		ircClient.Privmsg(target, fmt.Sprintf("Correct, %s, but %v.", user, err))
+		return
+	}
+	value := triviaGame.ClueValue(q)
+	triviaGame.AwardCorrectFor(user, value, game.ReasonCorrect, q)
+	who := user
+	if side != user {
+		who = fmt.Sprintf("%s (%s)", user, side)
+	}
+	channel := triviaGame.GameChannel
+	ircClient.Privmsg(channel, fmt.Sprintf("Correct, %s! (+%s)", who, game.FormatDollars(value)))
+	if !first {
+		return
+	}
+	ircClient.Privmsg(channel, fmt.Sprintf("%s got it first! Other teams have %s to answer too; send me your answer privately to keep it secret, e.g. \"answer <text>\".", who, window))
+
+	// The window replaces the question's timer
+	if triviaGame.QuestionTimer != nil {
+		triviaGame.QuestionTimer.Stop()
+	}
+	triviaGame.QuestionTimer = time.AfterFunc(window, func() {
+		if _, closed := triviaGame.CloseQuestion(q); !closed {
+			return // Skipped or stopped in the meantime
+		}
+		ircClient.Privmsg(channel, fmt.Sprintf("Time's up! The answer was: %s", q.Answer))
+		if triviaGame.GetPlaying() {
+			triviaGame.AnswerGiven <- true // Signal that the question was answered
+		}
+	})
+}
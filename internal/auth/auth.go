@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role is a privilege level. Higher roles include everything lower roles may do.
+type Role int
+
+const (
+	Player Role = iota // Anyone
+	Moderator
+	Admin
+	Owner
+)
+
+var roleNames = []string{"player", "moderator", "admin", "owner"}
+
+// String returns the lower-case name of the role.
+func (r Role) String() string {
+	if r < Player || int(r) >= len(roleNames) {
+		return fmt.Sprintf("role(%d)", int(r))
+	}
+	return roleNames[r]
+}
+
+// ParseRole parses a role name such as "admin", ignoring case.
+func ParseRole(s string) (Role, error) {
+	for i, name := range roleNames {
+		if strings.EqualFold(s, name) {
+			return Role(i), nil
+		}
+	}
+	return Player, fmt.Errorf("unknown role %q", s)
+}
+
+// statusRanks lists channel status prefixes from highest to lowest.
+const statusRanks = "~&@%+"
+
+// Identity describes the user behind a command.
+type Identity struct {
+	Nick     string
+	Hostmask string // nick!user@host, when known
+	Account  string // Services account; empty when not logged in or unknown
+	Status   string // Channel status prefixes, e.g. "@" or "@+"
+}
+
+// rule grants a role to users matching one of account, mask or status.
+type rule struct {
+	role    Role
+	account string // Services account name
+	mask    string // Hostmask glob, e.g. "*!*@staff.example.org"
+	status  byte   // Channel status prefix; higher statuses match too
+}
+
+func (r rule) matches(id Identity) bool {
+	switch {
+	case r.account != "":
+		return id.Account != "" && strings.EqualFold(r.account, id.Account)
+	case r.mask != "":
+		return id.Hostmask != "" && MatchMask(r.mask, id.Hostmask)
+	default:
+		rank := strings.IndexByte(statusRanks, r.status)
+		for i := 0; i < len(id.Status); i++ {
+			if j := strings.IndexByte(statusRanks, id.Status[i]); j >= 0 && j <= rank {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Authorizer maps identities to roles using configured rules.
+type Authorizer struct {
+	rules []rule
+}
+
+// NewAuthorizer builds an authorizer from a comma-separated list of entries
+// per role. Each entry is one of:
+//
+//   - "account:name" to match a services account,
+//   - a hostmask glob containing '!' or '@', e.g. "*!*@staff.example.org", or
+//   - a channel status prefix ("~", "&", "@", "%" or "+"), which also matches
+//     anyone with a higher status.
+func NewAuthorizer(entries map[Role]string) (*Authorizer, error) {
+	a := &Authorizer{}
+	for role, list := range entries {
+		for _, entry := range strings.Split(list, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			r := rule{role: role}
+			switch {
+			case strings.HasPrefix(strings.ToLower(entry), "account:"):
+				r.account = strings.TrimSpace(entry[len("account:"):])
+				if r.account == "" {
+					return nil, fmt.Errorf("%s entry %q has no account name", role, entry)
+				}
+			case strings.ContainsAny(entry, "!@") && len(entry) > 1:
+				r.mask = entry
+			case len(entry) == 1 && strings.Contains(statusRanks, entry):
+				r.status = entry[0]
+			default:
+				return nil, fmt.Errorf("%s entry %q is not an account:, hostmask or channel status", role, entry)
+			}
+			a.rules = append(a.rules, r)
+		}
+	}
+	return a, nil
+}
+
+// Role returns the highest role granted to id.
+func (a *Authorizer) Role(id Identity) Role {
+	best := Player
+	for _, r := range a.rules {
+		if r.role > best && r.matches(id) {
+			best = r.role
+		}
+	}
+	return best
+}
+
+// Allowed reports whether id holds at least the given role.
+func (a *Authorizer) Allowed(id Identity, role Role) bool {
+	return role <= Player || a.Role(id) >= role
+}
+
+// MatchMask reports whether s matches the glob pattern, where '*' matches any
+// run of characters and '?' matches exactly one. Matching ignores case.
+func MatchMask(pattern, s string) bool {
+	p, str := []rune(strings.ToLower(pattern)), []rune(strings.ToLower(s))
+	pi, si := 0, 0
+	star, mark := -1, 0
+	for si < len(str) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == str[si]):
+			pi++
+			si++
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, si
+			pi++
+		case star >= 0:
+			pi = star + 1
+			mark++
+			si = mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
@@ -0,0 +1,91 @@
+package auth
+
+import "testing"
+
+func TestMatchMask(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		expected   bool
+	}{
+		{"*!*@staff.example.org", "alice!al@staff.example.org", true},
+		{"*!*@staff.example.org", "alice!al@evil.example.org", false},
+		{"*!*@*.EXAMPLE.org", "alice!al@staff.example.org", true},
+		{"alice!*@*", "Alice!al@host", true},
+		{"alice!*@*", "alicex!al@host", false},
+		{"a?ice!*@*", "alice!al@host", true},
+		{"*", "", true},
+		{"?", "", false},
+		{"*!*@*.*.*", "bob!b@a.b", false},
+		{"**x", "abcx", true},
+	}
+	for _, tt := range tests {
+		if got := MatchMask(tt.pattern, tt.s); got != tt.expected {
+			t.Errorf("MatchMask(%q, %q) = %v, expected %v", tt.pattern, tt.s, got, tt.expected)
+		}
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	for _, role := range []Role{Player, Moderator, Admin, Owner} {
+		parsed, err := ParseRole(role.String())
+		if err != nil || parsed != role {
+			t.Errorf("ParseRole(%q) = %v, %v", role.String(), parsed, err)
+		}
+	}
+	if r, err := ParseRole("ADMIN"); err != nil || r != Admin {
+		t.Errorf("ParseRole should ignore case, got %v, %v", r, err)
+	}
+	if _, err := ParseRole("superuser"); err == nil {
+		t.Error("Expected an error for an unknown role")
+	}
+}
+
+func TestAuthorizer(t *testing.T) {
+	a, err := NewAuthorizer(map[Role]string{
+		Owner:     "account:Alex",
+		Admin:     "@, *!*@staff.example.org",
+		Moderator: "%",
+	})
+	if err != nil {
+		t.Fatalf("NewAuthorizer failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		id       Identity
+		expected Role
+	}{
+		{"NoMatch", Identity{Nick: "bob", Hostmask: "bob!b@home.net"}, Player},
+		{"Account", Identity{Nick: "trebek", Hostmask: "trebek!t@home.net", Account: "alex"}, Owner},
+		{"AccountNotNick", Identity{Nick: "alex", Hostmask: "alex!a@home.net"}, Player},
+		{"Hostmask", Identity{Nick: "carol", Hostmask: "carol!c@staff.example.org"}, Admin},
+		{"Op", Identity{Nick: "dave", Status: "@"}, Admin},
+		{"HigherThanOp", Identity{Nick: "erin", Status: "~"}, Admin},
+		{"Halfop", Identity{Nick: "frank", Status: "%+"}, Moderator},
+		{"Voice", Identity{Nick: "grace", Status: "+"}, Player},
+		{"BestRuleWins", Identity{Nick: "alex", Account: "alex", Status: "@"}, Owner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.Role(tt.id); got != tt.expected {
+				t.Errorf("Role() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+
+	op := Identity{Nick: "dave", Status: "@"}
+	if !a.Allowed(op, Moderator) || !a.Allowed(op, Admin) || a.Allowed(op, Owner) {
+		t.Error("Operators should be allowed moderator and admin commands only")
+	}
+	if !a.Allowed(Identity{Nick: "bob"}, Player) {
+		t.Error("Everyone should be allowed player commands")
+	}
+}
+
+func TestNewAuthorizerErrors(t *testing.T) {
+	for _, entry := range []string{"account:", "bob", "!", "*"} {
+		if _, err := NewAuthorizer(map[Role]string{Admin: entry}); err == nil {
+			t.Errorf("Expected an error for entry %q", entry)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"trebek/internal/auth"
+)
+
+// Permission is the minimum role needed to run a command.
+type Permission = auth.Role
+
+const (
+	PermissionPlayer    = auth.Player // Anyone
+	PermissionModerator = auth.Moderator
+	PermissionAdmin     = auth.Admin
+	PermissionOwner     = auth.Owner
+)
+
+// Command describes a single bot command.
+type Command struct {
+	Name       string        // Primary name, without prefix
+	Aliases    []string      // Alternative names
+	Usage      string        // Argument syntax, e.g. "<your answer>"
+	Help       string        // One-line description
+	MinArgs    int           // Fewer arguments than this replies with the usage
+	Permission Permission    // Minimum level needed to run the command
+	Cooldown   time.Duration // Minimum time between uses by the same user in the same channel
+	Handler    func(ctx *Context)
+}
+
+// Context is everything a handler needs to know about one invocation.
+type Context struct {
+	User    string   // Nick of the user who ran the command
+	Target  string   // Where replies go: the channel, or the user for private messages
+	Channel string   // Channel whose game the command applies to
+	Private bool     // True when the command arrived as a private message
+	Args    []string // Whitespace-separated arguments
+	Raw     string   // Argument text exactly as typed, trimmed
+	Command *Command // The command being run
+	Prefix  string   // Command prefix, for replies that mention other commands
+
+	// Reply sends a message back to Target.
+	Reply func(message string)
+}
+
+// Replyf formats and sends a reply.
+func (ctx *Context) Replyf(format string, args ...any) {
+	ctx.Reply(fmt.Sprintf(format, args...))
+}
+
+// Registry holds the registered commands and dispatches input to them.
+type Registry struct {
+	Prefix string // Shown in help output
+
+	// Authorize reports whether the invoking user holds the given permission.
+	// When nil, only player-level commands may run.
+	Authorize func(ctx *Context, perm Permission) bool
+
+	mu       sync.Mutex
+	commands map[string]*Command // Keyed by lower-cased name and aliases
+	ordered  []*Command          // In registration order, for help output
+	lastUsed map[string]time.Time
+	now      func() time.Time
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry(prefix string) *Registry {
+	return &Registry{
+		Prefix:   prefix,
+		commands: make(map[string]*Command),
+		lastUsed: make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// HelpCommand returns a help command whose output is generated from the registry.
+func (r *Registry) HelpCommand() *Command {
+	return &Command{
+		Name:    "help",
+		Aliases: []string{"commands"},
+		Usage:   "[command]",
+		Help:    "List commands, or explain one command.",
+		Handler: r.help,
+	}
+}
+
+// Register adds a command. It fails if the name or an alias is already taken.
+func (r *Registry) Register(cmd *Command) error {
+	if cmd.Name == "" || cmd.Handler == nil {
+		return fmt.Errorf("command needs a name and a handler")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := append([]string{cmd.Name}, cmd.Aliases...)
+	for _, name := range names {
+		if _, ok := r.commands[strings.ToLower(name)]; ok {
+			return fmt.Errorf("command name %q is already registered", name)
+		}
+	}
+	for _, name := range names {
+		r.commands[strings.ToLower(name)] = cmd
+	}
+	r.ordered = append(r.ordered, cmd)
+	return nil
+}
+
+// MustRegister is like Register but panics on error. It is meant for
+// registering the bot's fixed command set at startup.
+func (r *Registry) MustRegister(cmd *Command) {
+	if err := r.Register(cmd); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup finds a command by name or alias, ignoring case and any prefix.
+func (r *Registry) Lookup(name string) *Command {
+	name = strings.TrimPrefix(strings.ToLower(name), strings.ToLower(r.Prefix))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.commands[name]
+}
+
+// Commands returns the registered commands in registration order.
+func (r *Registry) Commands() []*Command {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Command(nil), r.ordered...)
+}
+
+// Dispatch runs the command named by the first word of input, which must not
+// include the prefix. It returns false if no such command exists; permission,
+// usage and cooldown problems are reported to the user and count as handled.
+func (r *Registry) Dispatch(ctx *Context, input string) bool {
+	name, rest, _ := strings.Cut(strings.TrimSpace(input), " ")
+	cmd := r.Lookup(name)
+	if cmd == nil {
+		return false
+	}
+
+	ctx.Command = cmd
+	ctx.Prefix = r.Prefix
+	ctx.Raw = strings.TrimSpace(rest)
+	ctx.Args = strings.Fields(rest)
+
+	if !r.allowed(ctx, cmd.Permission) {
+		ctx.Replyf("Sorry, %s, %s%s needs %s access.", ctx.User, r.Prefix, cmd.Name, cmd.Permission)
+		return true
+	}
+	if len(ctx.Args) < cmd.MinArgs {
+		ctx.Replyf("Usage: %s", r.usage(cmd))
+		return true
+	}
+	if wait := r.cooldown(ctx, cmd); wait > 0 {
+		ctx.Replyf("%s, please wait %s before using %s%s again.", ctx.User, wait.Round(time.Second), r.Prefix, cmd.Name)
+		return true
+	}
+
+	cmd.Handler(ctx)
+	return true
+}
+
+func (r *Registry) allowed(ctx *Context, perm Permission) bool {
+	if perm <= PermissionPlayer {
+		return true
+	}
+	return r.Authorize != nil && r.Authorize(ctx, perm)
+}
+
+// cooldown returns how much longer the user has to wait, recording the use
+// when the command is allowed to run.
+func (r *Registry) cooldown(ctx *Context, cmd *Command) time.Duration {
+	if cmd.Cooldown <= 0 {
+		return 0
+	}
+	key := strings.ToLower(cmd.Name + " " + ctx.Channel + " " + ctx.User)
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if last, ok := r.lastUsed[key]; ok {
+		if wait := cmd.Cooldown - now.Sub(last); wait > 0 {
+			return wait
+		}
+	}
+	r.lastUsed[key] = now
+	return 0
+}
+
+// usage returns the command's syntax, e.g. "!answer <your answer>".
+func (r *Registry) usage(cmd *Command) string {
+	if cmd.Usage == "" {
+		return r.Prefix + cmd.Name
+	}
+	return r.Prefix + cmd.Name + " " + cmd.Usage
+}
+
+// Help describes a single command, or returns "" if it does not exist.
+func (r *Registry) Help(name string) string {
+	cmd := r.Lookup(name)
+	if cmd == nil {
+		return ""
+	}
+	text := fmt.Sprintf("%s - %s", r.usage(cmd), cmd.Help)
+	if len(cmd.Aliases) > 0 {
+		aliases := make([]string, len(cmd.Aliases))
+		for i, a := range cmd.Aliases {
+			aliases[i] = r.Prefix + a
+		}
+		text += " Aliases: " + strings.Join(aliases, ", ") + "."
+	}
+	if cmd.Permission > PermissionPlayer {
+		text += fmt.Sprintf(" Requires %s.", cmd.Permission)
+	}
+	if cmd.Cooldown > 0 {
+		text += fmt.Sprintf(" Cooldown: %s.", cmd.Cooldown)
+	}
+	return text
+}
+
+// Summary lists every command name in registration order for the overall help output.
+func (r *Registry) Summary() string {
+	cmds := r.Commands()
+	names := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		names[i] = r.Prefix + cmd.Name
+	}
+	return fmt.Sprintf("Commands: %s. Type %shelp <command> for details.", strings.Join(names, ", "), r.Prefix)
+}
+
+func (r *Registry) help(ctx *Context) {
+	if len(ctx.Args) == 0 {
+		ctx.Reply(r.Summary())
+		return
+	}
+	if text := r.Help(ctx.Args[0]); text != "" {
+		ctx.Reply(text)
+		return
+	}
+	ctx.Replyf("Unknown command: %s. Type %shelp for commands.", ctx.Args[0], r.Prefix)
+}
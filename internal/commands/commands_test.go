@@ -0,0 +1,206 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestContext returns a context whose replies are collected in the returned slice.
+func newTestContext(user string) (*Context, *[]string) {
+	var replies []string
+	ctx := &Context{
+		User:    user,
+		Target:  "#trivia",
+		Channel: "#trivia",
+		Reply:   func(message string) { replies = append(replies, message) },
+	}
+	return ctx, &replies
+}
+
+func TestDispatchExactNames(t *testing.T) {
+	r := NewRegistry("!")
+	var ran []string
+	for _, name := range []string{"score", "start"} {
+		r.MustRegister(&Command{Name: name, Handler: func(ctx *Context) { ran = append(ran, name) }})
+	}
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"score", true},
+		{"SCORE", true},
+		{"scoreboard", false},
+		{"starts", false},
+		{"start now please", true},
+		{"", false},
+	}
+	for _, tt := range tests {
+		ctx, _ := newTestContext("alice")
+		if got := r.Dispatch(ctx, tt.input); got != tt.expected {
+			t.Errorf("Dispatch(%q) = %v, expected %v", tt.input, got, tt.expected)
+		}
+	}
+	if strings.Join(ran, ",") != "score,score,start" {
+		t.Errorf("Unexpected handlers run: %v", ran)
+	}
+}
+
+func TestDispatchArgsAndAliases(t *testing.T) {
+	r := NewRegistry("!")
+	var got *Context
+	r.MustRegister(&Command{
+		Name:    "answer",
+		Aliases: []string{"a"},
+		Usage:   "<your answer>",
+		MinArgs: 1,
+		Handler: func(ctx *Context) { got = ctx },
+	})
+
+	ctx, replies := newTestContext("alice")
+	r.Dispatch(ctx, "a  The  Nile ")
+	if got == nil {
+		t.Fatal("Alias did not run the handler")
+	}
+	if got.Raw != "The  Nile" {
+		t.Errorf("Expected raw args %q, got %q", "The  Nile", got.Raw)
+	}
+	if len(got.Args) != 2 || got.Args[0] != "The" || got.Args[1] != "Nile" {
+		t.Errorf("Unexpected args: %q", got.Args)
+	}
+	if got.Command.Name != "answer" {
+		t.Errorf("Expected command answer, got %s", got.Command.Name)
+	}
+	if len(*replies) != 0 {
+		t.Errorf("Expected no replies, got %v", *replies)
+	}
+
+	got = nil
+	ctx, replies = newTestContext("alice")
+	r.Dispatch(ctx, "answer")
+	if got != nil {
+		t.Error("Handler ran without its required argument")
+	}
+	if len(*replies) != 1 || (*replies)[0] != "Usage: !answer <your answer>" {
+		t.Errorf("Expected usage reply, got %v", *replies)
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	r := NewRegistry("!")
+	noop := func(*Context) {}
+	if err := r.Register(&Command{Name: "skip", Aliases: []string{"next"}, Handler: noop}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := r.Register(&Command{Name: "Next", Handler: noop}); err == nil {
+		t.Error("Expected an error registering a name that is already an alias")
+	}
+	if err := r.Register(&Command{Name: "", Handler: noop}); err == nil {
+		t.Error("Expected an error registering a command without a name")
+	}
+	if len(r.Commands()) != 1 {
+		t.Errorf("Expected 1 command, got %d", len(r.Commands()))
+	}
+}
+
+func TestPermission(t *testing.T) {
+	r := NewRegistry("!")
+	ran := false
+	r.MustRegister(&Command{
+		Name:       "resetscoreboard",
+		Permission: PermissionAdmin,
+		Handler:    func(*Context) { ran = true },
+	})
+
+	// Without an authorizer only player commands run
+	ctx, replies := newTestContext("mallory")
+	r.Dispatch(ctx, "resetscoreboard")
+	if ran {
+		t.Error("Admin command ran without an authorizer")
+	}
+	if len(*replies) != 1 || !strings.Contains((*replies)[0], "needs admin access") {
+		t.Errorf("Expected a permission reply, got %v", *replies)
+	}
+
+	r.Authorize = func(ctx *Context, perm Permission) bool {
+		return ctx.User == "alice" && perm <= PermissionAdmin
+	}
+	ctx, _ = newTestContext("mallory")
+	r.Dispatch(ctx, "resetscoreboard")
+	if ran {
+		t.Error("Admin command ran for an unauthorized user")
+	}
+	ctx, _ = newTestContext("alice")
+	r.Dispatch(ctx, "resetscoreboard")
+	if !ran {
+		t.Error("Admin command did not run for an authorized user")
+	}
+}
+
+func TestCooldown(t *testing.T) {
+	r := NewRegistry("!")
+	now := time.Unix(0, 0)
+	r.now = func() time.Time { return now }
+	runs := 0
+	r.MustRegister(&Command{Name: "hint", Cooldown: 10 * time.Second, Handler: func(*Context) { runs++ }})
+
+	ctx, _ := newTestContext("alice")
+	r.Dispatch(ctx, "hint")
+	ctx, replies := newTestContext("alice")
+	now = now.Add(4 * time.Second)
+	r.Dispatch(ctx, "hint")
+	if runs != 1 {
+		t.Errorf("Expected the second use to be rate limited, got %d runs", runs)
+	}
+	if len(*replies) != 1 || !strings.Contains((*replies)[0], "please wait 6s") {
+		t.Errorf("Expected a cooldown reply, got %v", *replies)
+	}
+
+	// Other users are not affected
+	ctx, _ = newTestContext("bob")
+	r.Dispatch(ctx, "hint")
+	if runs != 2 {
+		t.Errorf("Expected another user to run the command, got %d runs", runs)
+	}
+
+	now = now.Add(6 * time.Second)
+	ctx, _ = newTestContext("alice")
+	r.Dispatch(ctx, "hint")
+	if runs != 3 {
+		t.Errorf("Expected the command to run after the cooldown, got %d runs", runs)
+	}
+}
+
+func TestHelp(t *testing.T) {
+	r := NewRegistry("!")
+	noop := func(*Context) {}
+	r.MustRegister(&Command{Name: "start", Help: "Start trivia.", Handler: noop})
+	r.MustRegister(&Command{
+		Name:       "answer",
+		Aliases:    []string{"a"},
+		Usage:      "<your answer>",
+		Help:       "Answer the current question.",
+		Permission: PermissionModerator,
+		Cooldown:   5 * time.Second,
+		Handler:    noop,
+	})
+	r.MustRegister(r.HelpCommand())
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"help", "Commands: !start, !answer, !help. Type !help <command> for details."},
+		{"help start", "!start - Start trivia."},
+		{"help !A", "!answer <your answer> - Answer the current question. Aliases: !a. Requires moderator. Cooldown: 5s."},
+		{"commands nope", "Unknown command: nope. Type !help for commands."},
+	}
+	for _, tt := range tests {
+		ctx, replies := newTestContext("alice")
+		r.Dispatch(ctx, tt.input)
+		if len(*replies) != 1 || (*replies)[0] != tt.expected {
+			t.Errorf("Dispatch(%q) replied %q, expected %q", tt.input, *replies, tt.expected)
+		}
+	}
+}
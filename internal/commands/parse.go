@@ -0,0 +1,44 @@
+package commands
+
+import "strings"
+
+// Parse decides whether a message is a command and, if so, returns the
+// command input without its prefix, ready for Dispatch. A message is a
+// command when it
+//
+//   - starts with the registry prefix ("!start"),
+//   - is addressed to the bot by nick ("TrebekBot: start", "TrebekBot, hint"),
+//     optionally with the prefix as well, or
+//   - arrives as a private message, where the prefix is optional.
+func (r *Registry) Parse(message, nick string, private bool) (string, bool) {
+	message = strings.TrimSpace(message)
+
+	if rest, ok := addressed(message, nick); ok {
+		message = rest
+		private = true // Addressed commands need no prefix, exactly like private ones
+	}
+
+	if r.Prefix != "" && strings.HasPrefix(message, r.Prefix) {
+		message = strings.TrimSpace(message[len(r.Prefix):])
+	} else if !private {
+		return "", false
+	}
+
+	if message == "" {
+		return "", false
+	}
+	return message, true
+}
+
+// addressed strips a leading "nick:" or "nick," from message, matching the
+// nick case-insensitively.
+func addressed(message, nick string) (string, bool) {
+	if nick == "" || len(message) <= len(nick) || !strings.EqualFold(message[:len(nick)], nick) {
+		return "", false
+	}
+	switch message[len(nick)] {
+	case ':', ',':
+		return strings.TrimSpace(message[len(nick)+1:]), true
+	}
+	return "", false
+}
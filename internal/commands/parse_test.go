@@ -0,0 +1,45 @@
+package commands
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		message  string
+		private  bool
+		expected string
+		ok       bool
+	}{
+		{"!", "!start", false, "start", true},
+		{"!", "! start", false, "start", true},
+		{"!", "start", false, "", false},
+		{"!", "!", false, "", false},
+		{"!", "hello there", false, "", false},
+		{"t!", "t!hint", false, "hint", true},
+		{"t!", "!hint", false, "", false},
+		{".", ".answer the Nile", false, "answer the Nile", true},
+
+		// Addressed by nick
+		{"!", "TrebekBot: start", false, "start", true},
+		{"!", "trebekbot, hint", false, "hint", true},
+		{"!", "TrebekBot: !score", false, "score", true},
+		{"!", "TrebekBot:", false, "", false},
+		{"!", "TrebekBot is great", false, "", false},
+		{"!", "TrebekBot2: start", false, "", false},
+		{"!", "Trebek: start", false, "", false},
+
+		// Private messages need no prefix
+		{"!", "score", true, "score", true},
+		{"!", "!score", true, "score", true},
+		{"t!", "topscores", true, "topscores", true},
+		{"!", "  ", true, "", false},
+	}
+	for _, tt := range tests {
+		r := NewRegistry(tt.prefix)
+		got, ok := r.Parse(tt.message, "TrebekBot", tt.private)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("Parse(%q, private=%v) with prefix %q = %q, %v; expected %q, %v",
+				tt.message, tt.private, tt.prefix, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -12,9 +13,65 @@ type Config struct {
 	BotName      string
 	IRCServer    string
 	IRCServerTLS string
-	IRCChannel   string
+	IRCChannel   string // One channel, or several separated by commas
 	LogFilePath  string
 	LogLevel     string
+
+	// SASL authentication. Only read from the config file and environment so
+	// credentials never show up in the process list.
+	SASLMechanism string // PLAIN or EXTERNAL; empty disables SASL
+	SASLUsername  string
+	SASLPassword  string
+	TLSClientCert string // PEM certificate presented to the server, used by SASL EXTERNAL
+	TLSClientKey  string // PEM private key for TLSClientCert
+
+	AltNicks         string // Comma-separated nicks to try when BOT_NAME is taken
+	NickServPassword string // Used to GHOST whoever holds BOT_NAME
+
+	FloodBurst int     // Lines the bot may send back to back; 0 uses the client default
+	FloodRate  float64 // Sustained lines per second after the burst; 0 uses the client default
+
+	CommandPrefix string // Characters that start a channel command, "!" by default
+
+	// Who may run privileged commands. Each is a comma-separated list of
+	// "account:name" entries, hostmask globs and channel status prefixes.
+	Owners     string
+	Admins     string // Channel operators ("@") by default
+	Moderators string // Channel half-operators ("%") by default
+
+	// Game settings such as QUESTION_TIMEOUT or ANSWER_STRICTNESS, by key.
+	// Each value is a default that may be followed by per-channel overrides,
+	// e.g. "normal,#trivia-hard=strict". Values are validated by the game.
+	GameSettings map[string]string
+}
+
+// GameSettingKeys are the config keys passed on to each channel's game settings.
+var GameSettingKeys = []string{
+	"QUESTION_TIMEOUT",
+	"QUESTION_INTERVAL",
+	"ANSWER_DELAY",
+	"MAX_HINTS",
+	"SKIP_VOTES",
+	"DEFAULT_CLUE_VALUE",
+	"HINT_PENALTY",
+	"ANSWER_STRICTNESS",
+	"STANDINGS_EVERY",
+	"FINAL_JEOPARDY",
+	"FINAL_TIME",
+	"DAILY_DOUBLE_CHANCE",
+	"TEAM_MODE",
+	"TEAM_WINDOW",
+	"DUEL_WINDOW",
+	"ANNOUNCE_WINNERS",
+}
+
+func isGameSettingKey(key string) bool {
+	for _, k := range GameSettingKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
 }
 
 // LoadConfig loads configuration from the specified file path, environment variables, and command-line flags.
@@ -31,7 +88,7 @@ func LoadConfig(
 	cfg := &Config{} // Initialize with zero values
 
 	// 1. Load from config file
-	fileConfig := &Config{}
+	fileConfig := &Config{GameSettings: make(map[string]string)}
 	if filePath != "" {
 		file, err := os.Open(filePath) // #nosec G304
 		if err == nil {
@@ -64,7 +121,45 @@ func LoadConfig(
 					fileConfig.LogFilePath = value
 				case "LOG_LEVEL":
 					fileConfig.LogLevel = value
+				case "SASL_MECHANISM":
+					fileConfig.SASLMechanism = value
+				case "SASL_USERNAME":
+					fileConfig.SASLUsername = value
+				case "SASL_PASSWORD":
+					fileConfig.SASLPassword = value
+				case "TLS_CLIENT_CERT":
+					fileConfig.TLSClientCert = value
+				case "TLS_CLIENT_KEY":
+					fileConfig.TLSClientKey = value
+				case "ALT_NICKS":
+					fileConfig.AltNicks = value
+				case "NICKSERV_PASSWORD":
+					fileConfig.NickServPassword = value
+				case "FLOOD_BURST":
+					burst, err := strconv.Atoi(value)
+					if err != nil {
+						return nil, fmt.Errorf("invalid FLOOD_BURST %q: %w", value, err)
+					}
+					fileConfig.FloodBurst = burst
+				case "FLOOD_RATE":
+					rate, err := strconv.ParseFloat(value, 64)
+					if err != nil {
+						return nil, fmt.Errorf("invalid FLOOD_RATE %q: %w", value, err)
+					}
+					fileConfig.FloodRate = rate
+				case "COMMAND_PREFIX":
+					fileConfig.CommandPrefix = value
+				case "OWNERS":
+					fileConfig.Owners = value
+				case "ADMINS":
+					fileConfig.Admins = value
+				case "MODERATORS":
+					fileConfig.Moderators = value
 				default:
+					if isGameSettingKey(key) {
+						fileConfig.GameSettings[key] = value
+						continue
+					}
 					fmt.Printf("Warning: Unknown config key '%s'\n", key)
 				}
 			}
@@ -84,6 +179,20 @@ func LoadConfig(
 	cfg.IRCChannel = fileConfig.IRCChannel
 	cfg.LogFilePath = fileConfig.LogFilePath
 	cfg.LogLevel = fileConfig.LogLevel
+	cfg.SASLMechanism = fileConfig.SASLMechanism
+	cfg.SASLUsername = fileConfig.SASLUsername
+	cfg.SASLPassword = fileConfig.SASLPassword
+	cfg.TLSClientCert = fileConfig.TLSClientCert
+	cfg.TLSClientKey = fileConfig.TLSClientKey
+	cfg.AltNicks = fileConfig.AltNicks
+	cfg.NickServPassword = fileConfig.NickServPassword
+	cfg.FloodBurst = fileConfig.FloodBurst
+	cfg.FloodRate = fileConfig.FloodRate
+	cfg.CommandPrefix = fileConfig.CommandPrefix
+	cfg.Owners = fileConfig.Owners
+	cfg.Admins = fileConfig.Admins
+	cfg.Moderators = fileConfig.Moderators
+	cfg.GameSettings = fileConfig.GameSettings
 
 	// 2. Override with environment variables
 	if env := os.Getenv("BOT_NAME"); env != "" {
@@ -104,6 +213,58 @@ func LoadConfig(
 	if env := os.Getenv("LOG_LEVEL"); env != "" {
 		cfg.LogLevel = env
 	}
+	if env := os.Getenv("SASL_MECHANISM"); env != "" {
+		cfg.SASLMechanism = env
+	}
+	if env := os.Getenv("SASL_USERNAME"); env != "" {
+		cfg.SASLUsername = env
+	}
+	if env := os.Getenv("SASL_PASSWORD"); env != "" {
+		cfg.SASLPassword = env
+	}
+	if env := os.Getenv("TLS_CLIENT_CERT"); env != "" {
+		cfg.TLSClientCert = env
+	}
+	if env := os.Getenv("TLS_CLIENT_KEY"); env != "" {
+		cfg.TLSClientKey = env
+	}
+	if env := os.Getenv("ALT_NICKS"); env != "" {
+		cfg.AltNicks = env
+	}
+	if env := os.Getenv("NICKSERV_PASSWORD"); env != "" {
+		cfg.NickServPassword = env
+	}
+	if env := os.Getenv("FLOOD_BURST"); env != "" {
+		burst, err := strconv.Atoi(env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FLOOD_BURST %q: %w", env, err)
+		}
+		cfg.FloodBurst = burst
+	}
+	if env := os.Getenv("FLOOD_RATE"); env != "" {
+		rate, err := strconv.ParseFloat(env, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FLOOD_RATE %q: %w", env, err)
+		}
+		cfg.FloodRate = rate
+	}
+	if env := os.Getenv("COMMAND_PREFIX"); env != "" {
+		cfg.CommandPrefix = env
+	}
+	if env := os.Getenv("OWNERS"); env != "" {
+		cfg.Owners = env
+	}
+	if env := os.Getenv("ADMINS"); env != "" {
+		cfg.Admins = env
+	}
+	if env := os.Getenv("MODERATORS"); env != "" {
+		cfg.Moderators = env
+	}
+	for _, key := range GameSettingKeys {
+		if env := os.Getenv(key); env != "" {
+			cfg.GameSettings[key] = env
+		}
+	}
 
 	// 3. Override with command-line flags
 	if botNameFlag != "" {
@@ -132,9 +293,69 @@ func LoadConfig(
 	if cfg.IRCServer == "" && cfg.IRCServerTLS == "" {
 		return nil, fmt.Errorf("at least one of IRC_SERVER or IRC_SERVER_TLS must be set in config, environment, or flags")
 	}
-	if cfg.IRCChannel == "" {
+	if len(cfg.Channels()) == 0 {
 		return nil, fmt.Errorf("IRC_CHANNEL is not set in config, environment, or flags")
 	}
+	if cfg.CommandPrefix == "" {
+		cfg.CommandPrefix = "!"
+	}
+	if cfg.Admins == "" {
+		cfg.Admins = "@"
+	}
+	if cfg.Moderators == "" {
+		cfg.Moderators = "%"
+	}
+	if strings.ContainsAny(cfg.CommandPrefix, " \t") {
+		return nil, fmt.Errorf("COMMAND_PREFIX %q must not contain whitespace", cfg.CommandPrefix)
+	}
+	switch strings.ToUpper(cfg.SASLMechanism) {
+	case "":
+	case "PLAIN":
+		if cfg.SASLUsername == "" || cfg.SASLPassword == "" {
+			return nil, fmt.Errorf("SASL_MECHANISM=PLAIN requires SASL_USERNAME and SASL_PASSWORD")
+		}
+	case "EXTERNAL":
+		if cfg.TLSClientCert == "" || cfg.TLSClientKey == "" || cfg.IRCServerTLS == "" {
+			return nil, fmt.Errorf("SASL_MECHANISM=EXTERNAL requires IRC_SERVER_TLS, TLS_CLIENT_CERT and TLS_CLIENT_KEY")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported SASL_MECHANISM %q (use PLAIN or EXTERNAL)", cfg.SASLMechanism)
+	}
 
 	return cfg, nil
 }
+
+// Channels returns the channels listed in IRCChannel, in order and without duplicates.
+func (c *Config) Channels() []string {
+	var channels []string
+	seen := make(map[string]bool)
+	for _, ch := range strings.Split(c.IRCChannel, ",") {
+		ch = strings.TrimSpace(ch)
+		if ch == "" || seen[strings.ToLower(ch)] {
+			continue
+		}
+		seen[strings.ToLower(ch)] = true
+		channels = append(channels, ch)
+	}
+	return channels
+}
+
+// ForChannel picks a channel's value from a setting written as a default
+// followed by per-channel overrides, e.g. "normal,#trivia-hard=strict".
+// Channel names are matched case-insensitively.
+func ForChannel(setting, channel string) string {
+	var value string
+	for _, part := range strings.Split(setting, ",") {
+		part = strings.TrimSpace(part)
+		if ch, v, ok := strings.Cut(part, "="); ok {
+			if strings.EqualFold(strings.TrimSpace(ch), channel) {
+				return strings.TrimSpace(v)
+			}
+			continue
+		}
+		if value == "" {
+			value = part
+		}
+	}
+	return value
+}
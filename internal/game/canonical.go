@@ -0,0 +1,198 @@
+package game
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// maxOptionalGroups caps how many optional parentheticals are expanded, since
+// every group doubles the number of variants.
+const maxOptionalGroups = 4
+
+// questionPrefix matches the Jeopardy-style phrasing players put in front of
+// an answer, e.g. "What is", "Who are" or "What's".
+var questionPrefix = regexp.MustCompile(`(?i)^(what|who|where|when|which)(\s+(is|are|was|were)|'s|'re|s)\s+`)
+
+// leadingArticles are dropped from the front of answers and attempts.
+var leadingArticles = []string{"the ", "a ", "an "}
+
+// answerVariants expands a J-Archive answer into every form a player may give:
+//
+//   - parenthesized parts are optional: "(Ernest) Hemingway",
+//   - a parenthetical starting with "or", and "/" between words, give
+//     alternatives: "Mars (or the Red Planet)", "pepper/peppercorn". A bare
+//     "or" is part of the answer, as in "Truth or Consequences",
+//   - a trailing parenthetical that spells out the answer is an alternative:
+//     "1812 (the War of 1812)", and
+//   - leading articles are dropped: "the Nile".
+func answerVariants(answer string) []string {
+	var variants []string
+	seen := make(map[string]bool)
+	for _, expanded := range expandParentheticals(answer) {
+		for _, alt := range splitAlternatives(expanded) {
+			alt = stripArticle(strings.Join(strings.Fields(alt), " "))
+			key := normalizeAnswer(alt)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			variants = append(variants, alt)
+		}
+	}
+	if len(variants) == 0 {
+		variants = append(variants, answer) // Nothing but punctuation; compare as-is
+	}
+	return variants
+}
+
+// expandParentheticals returns the answer with every combination of its
+// optional parenthesized parts, plus any parentheticals that are alternatives.
+func expandParentheticals(answer string) []string {
+	type group struct {
+		start, end int    // Byte offsets of "(" and just past ")"
+		text       string // Contents without the parentheses
+	}
+	var groups []group
+	depth, start := 0, 0
+	for i, r := range answer {
+		switch r {
+		case '(':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case ')':
+			if depth == 0 {
+				continue // Stray ")"; normalization will drop it
+			}
+			depth--
+			if depth == 0 {
+				groups = append(groups, group{start, i + 1, answer[start+1 : i]})
+			}
+		}
+	}
+	if len(groups) == 0 {
+		return []string{answer}
+	}
+
+	// Everything outside the parentheses.
+	var base strings.Builder
+	prev := 0
+	for _, g := range groups {
+		base.WriteString(answer[prev:g.start])
+		prev = g.end
+	}
+	base.WriteString(answer[prev:])
+	baseKey := normalizeAnswer(base.String())
+
+	var alternatives []string
+	var optional []group
+	for i, g := range groups {
+		text := strings.TrimSpace(g.text)
+		lower := strings.ToLower(text)
+		switch {
+		case strings.HasPrefix(lower, "or "):
+			alternatives = append(alternatives, splitWord(text[3:], "or")...) // "(or X or Y)"
+		case i == len(groups)-1 && strings.TrimSpace(answer[g.end:]) == "" &&
+			baseKey != "" && strings.Contains(normalizeAnswer(text), baseKey):
+			alternatives = append(alternatives, text)
+		default:
+			optional = append(optional, g)
+		}
+	}
+	if len(optional) > maxOptionalGroups {
+		optional = optional[:maxOptionalGroups] // The rest are always left out
+	}
+
+	// Rebuild the answer for every subset of optional groups.
+	var expanded []string
+	for mask := 0; mask < 1<<len(optional); mask++ {
+		var b strings.Builder
+		prev := 0
+		next := 0
+		for _, g := range groups {
+			b.WriteString(answer[prev:g.start])
+			prev = g.end
+			if next < len(optional) && optional[next].start == g.start {
+				if mask&(1<<next) != 0 {
+					b.WriteString(" " + g.text + " ")
+				}
+				next++
+			}
+		}
+		b.WriteString(answer[prev:])
+		expanded = append(expanded, b.String())
+	}
+	return append(expanded, alternatives...)
+}
+
+// splitAlternatives splits "X/Y" into its parts. A slash between digits is a
+// fraction or date and a slash joining short parts is an abbreviation like
+// "AC/DC", so neither is split. The unsplit form of a slashed answer is kept
+// as well.
+func splitAlternatives(s string) []string {
+	parts := splitSlashes(s)
+	if len(parts) > 1 {
+		parts = append(parts, s)
+	}
+	return parts
+}
+
+// splitWord splits s around a whole word, ignoring case.
+func splitWord(s, word string) []string {
+	fields := strings.Fields(s)
+	var parts []string
+	start := 0
+	for i, f := range fields {
+		if strings.EqualFold(f, word) && i > start && i < len(fields)-1 {
+			parts = append(parts, strings.Join(fields[start:i], " "))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.Join(fields[start:], " "))
+}
+
+func splitSlashes(s string) []string {
+	var parts []string
+	runes := []rune(s)
+	start := 0
+	for i, r := range runes {
+		if r != '/' || i == 0 || i == len(runes)-1 {
+			continue
+		}
+		if unicode.IsDigit(runes[i-1]) && unicode.IsDigit(runes[i+1]) {
+			continue
+		}
+		parts = append(parts, string(runes[start:i]))
+		start = i + 1
+	}
+	parts = append(parts, string(runes[start:]))
+	for _, p := range parts {
+		if len([]rune(normalizeAnswer(p))) < 3 {
+			return []string{s}
+		}
+	}
+	return parts
+}
+
+// stripArticle drops a leading "the", "a" or "an" unless nothing would be left.
+func stripArticle(s string) string {
+	lower := strings.ToLower(s)
+	for _, article := range leadingArticles {
+		if strings.HasPrefix(lower, article) && strings.TrimSpace(s[len(article):]) != "" {
+			return strings.TrimSpace(s[len(article):])
+		}
+	}
+	return s
+}
+
+// canonicalAttempt removes the noise players add around an answer, such as
+// "What is" and leading articles.
+func canonicalAttempt(attempt string) string {
+	attempt = strings.Join(strings.Fields(attempt), " ")
+	if loc := questionPrefix.FindStringIndex(attempt); loc != nil && loc[1] < len(attempt) {
+		attempt = attempt[loc[1]:]
+	}
+	return stripArticle(attempt)
+}
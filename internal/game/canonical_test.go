@@ -0,0 +1,60 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnswerVariants(t *testing.T) {
+	tests := []struct {
+		answer   string
+		expected []string
+	}{
+		{"the Nile", []string{"Nile"}},
+		{"A Tale of Two Cities", []string{"Tale of Two Cities"}},
+		{"(Ernest) Hemingway", []string{"Hemingway", "Ernest Hemingway"}},
+		{"Mars (or the Red Planet)", []string{"Mars", "Red Planet"}},
+		{"1812 (the War of 1812)", []string{"1812", "War of 1812"}},
+		{"(Tom) Hanks (Jr.)", []string{"Hanks", "Tom Hanks", "Hanks Jr.", "Tom Hanks Jr."}},
+		{"pepper/peppercorn", []string{"pepper", "peppercorn", "pepper/peppercorn"}},
+		{"AC/DC", []string{"AC/DC"}},
+		{"1/2", []string{"1/2"}},
+		{"Lewis or Clark", []string{"Lewis or Clark"}},
+		{"Truth or Consequences", []string{"Truth or Consequences"}},
+		{"Mars (or the Red Planet or Ares)", []string{"Mars", "Red Planet", "Ares"}},
+		{"Oregon", []string{"Oregon"}},
+		{"The Who", []string{"Who"}},
+		{"The", []string{"The"}},
+		{"(the) Beatles (or the Fab Four)", []string{"Beatles", "Fab Four"}},
+		{"?!", []string{"?!"}},
+	}
+	for _, tt := range tests {
+		if got := answerVariants(tt.answer); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("answerVariants(%q) = %q, expected %q", tt.answer, got, tt.expected)
+		}
+	}
+}
+
+func TestCanonicalAttempt(t *testing.T) {
+	tests := []struct {
+		attempt  string
+		expected string
+	}{
+		{"What is the Nile?", "Nile?"},
+		{"what's  the Nile", "Nile"},
+		{"Who are the Beatles", "Beatles"},
+		{"WHO IS Hemingway", "Hemingway"},
+		{"whos Hemingway", "Hemingway"},
+		{"Where was Rome", "Rome"},
+		{"Who", "Who"},
+		{"The Who", "Who"},
+		{"Who are The Who", "Who"},
+		{"an apple", "apple"},
+		{"Theodore Roosevelt", "Theodore Roosevelt"},
+	}
+	for _, tt := range tests {
+		if got := canonicalAttempt(tt.attempt); got != tt.expected {
+			t.Errorf("canonicalAttempt(%q) = %q, expected %q", tt.attempt, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"trebek/internal/question"
+)
+
+// DailyDouble is the state of a Daily Double clue. Until a player claims it,
+// Player is empty and nobody may answer.
+type DailyDouble struct {
+	Player string // Player who claimed it and alone may answer
+	Wager  int
+}
+
+// rollDailyDouble decides whether a newly asked clue is a Daily Double. Only
+// regular clues in continuous play qualify. It must be called with g.mu held.
+func (g *Game) rollDailyDouble(q *question.Question) {
+	g.dailyDouble = nil
+	chance := g.settings.DailyDoubleChance
+	if !g.IsPlaying || chance <= 0 || IsFinalClue(q) {
+		return
+	}
+	if g.rand.Intn(100) < chance {
+		g.dailyDouble = &DailyDouble{}
+	}
+}
+
+// CurrentDailyDouble returns the current clue's Daily Double state and true,
+// or false if the current clue is not a Daily Double.
+func (g *Game) CurrentDailyDouble() (DailyDouble, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.dailyDouble == nil || g.CurrentQuestion == nil {
+		return DailyDouble{}, false
+	}
+	return *g.dailyDouble, true
+}
+
+// MaxDailyDoubleWager returns the most player may wager on the current clue:
+// their score, or the clue's value if that is more. During a match the match
+// score counts, otherwise the all-time score.
+func (g *Game) MaxDailyDoubleWager(player string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.maxDailyDoubleWager(player)
+}
+
+// maxDailyDoubleWager is MaxDailyDoubleWager for callers that hold g.mu.
+func (g *Game) maxDailyDoubleWager(player string) int {
+	if g.CurrentQuestion == nil {
+		return 0
+	}
+	var score int
+	if g.match != nil {
+		score = g.match.Scores[player]
+	} else {
+		score = g.Scoreboard.GetScore(player)
+	}
+	return max(score, g.clueValue(g.CurrentQuestion))
+}
+
+// ClaimDailyDouble gives player the current Daily Double with the given wager.
+func (g *Game) ClaimDailyDouble(player string, wager int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.dailyDouble == nil || g.CurrentQuestion == nil {
+		return errors.New("there is no Daily Double to claim")
+	}
+	if g.dailyDouble.Player != "" {
+		return fmt.Errorf("%s already claimed the Daily Double", g.dailyDouble.Player)
+	}
+	// The minimum wager is $5, unless the most the player can wager is less
+	limit := g.maxDailyDoubleWager(player)
+	floor := min(5, limit)
+	if wager < floor || wager > limit {
+		return fmt.Errorf("you can wager between %s and %s", FormatDollars(floor), FormatDollars(limit))
+	}
+	g.dailyDouble.Player = player
+	g.dailyDouble.Wager = wager
+	return nil
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+
+	"trebek/internal/question"
+)
+
+func TestDailyDouble(t *testing.T) {
+	originalScoreboardFile := scoreboardFile
+	scoreboardFile = filepath.Join(t.TempDir(), "scoreboard.json")
+	defer func() { scoreboardFile = originalScoreboardFile }()
+
+	game := NewGame(newMockQuestionSource([]*question.Question{
+		{Category: "RIVERS", Question: "It flows through Cairo", Answer: "the Nile", Money: "$400"},
+		{Category: "RIVERS", Question: "It flows through Baghdad", Answer: "the Tigris", Money: "$800"},
+	}), "#trivia")
+	game.Configure("daily_double_chance", "100")
+
+	// Only clues in continuous play can be Daily Doubles
+	game.StartRound()
+	if _, ok := game.CurrentDailyDouble(); ok {
+		t.Error("Expected no Daily Double outside continuous play")
+	}
+	if err := game.ClaimDailyDouble("alice", 100); err == nil {
+		t.Error("Expected a claim without a Daily Double to fail")
+	}
+	game.ClearCurrentQuestion()
+
+	game.SetPlaying(true)
+	game.AwardCorrect("bob", 2000)
+	game.StartRound()
+	if dd, ok := game.CurrentDailyDouble(); !ok || dd.Player != "" {
+		t.Fatalf("Expected an unclaimed Daily Double, got %+v, %v", dd, ok)
+	}
+	if limit := game.MaxDailyDoubleWager("alice"); limit != game.ClueValue(game.GetCurrentQuestion()) {
+		t.Errorf("Expected a player without money to wager up to the clue value, got %d", limit)
+	}
+	if limit := game.MaxDailyDoubleWager("bob"); limit != 2000 {
+		t.Errorf("Expected bob to wager up to their score, got %d", limit)
+	}
+	for _, wager := range []int{0, 4, 2001} {
+		if err := game.ClaimDailyDouble("bob", wager); err == nil {
+			t.Errorf("Expected a wager of %d to be refused", wager)
+		}
+	}
+	if err := game.ClaimDailyDouble("bob", 1500); err != nil {
+		t.Fatalf("ClaimDailyDouble failed: %v", err)
+	}
+	if err := game.ClaimDailyDouble("alice", 100); err == nil {
+		t.Error("Expected a second claim to fail")
+	}
+	if dd, _ := game.CurrentDailyDouble(); dd.Player != "bob" || dd.Wager != 1500 {
+		t.Errorf("Unexpected Daily Double: %+v", dd)
+	}
+
+	game.ClearCurrentQuestion()
+	if _, ok := game.CurrentDailyDouble(); ok {
+		t.Error("Expected the Daily Double to end with the question")
+	}
+
+	game.Configure("daily_double_chance", "0")
+	game.StartRound()
+	if _, ok := game.CurrentDailyDouble(); ok {
+		t.Error("Expected no Daily Double with a chance of 0")
+	}
+}
+
+func TestDailyDoubleWagerBelowMinimum(t *testing.T) {
+	originalScoreboardFile := scoreboardFile
+	scoreboardFile = filepath.Join(t.TempDir(), "scoreboard.json")
+	defer func() { scoreboardFile = originalScoreboardFile }()
+
+	game := NewGame(newMockQuestionSource([]*question.Question{
+		{Category: "RIVERS", Question: "It flows through Cairo", Answer: "the Nile", Money: "$3"},
+	}), "#trivia")
+	game.Configure("daily_double_chance", "100")
+	game.SetPlaying(true)
+	game.StartRound()
+
+	// With less than $5 to wager, everything the player has is the minimum too
+	if err := game.ClaimDailyDouble("alice", 4); err == nil {
+		t.Error("Expected a wager over the limit to be refused")
+	}
+	if err := game.ClaimDailyDouble("alice", 3); err != nil {
+		t.Errorf("Expected a wager of the whole limit to be allowed, got %v", err)
+	}
+}
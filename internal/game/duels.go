@@ -0,0 +1,266 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"trebek/internal/question"
+)
+
+var duelRecordFile = "duels.json"
+
+const (
+	DefaultDuelLength = 5           // Questions a duel is best of
+	maxDuelLength     = 15          // Longest duel that can be asked for
+	ChallengeTimeout  = time.Minute // Time a challenge can be accepted in
+)
+
+// A Duel is a head-to-head showdown between two players. Only they may
+// answer, and the first to win a majority of the questions wins the duel.
+type Duel struct {
+	Challenger   string
+	Opponent     string
+	BestOf       int
+	Wins         map[string]int // Questions won, by player
+	Asked        int            // Questions asked so far
+	Accepted     bool           // False while the challenge is open
+	ChallengedAt time.Time
+}
+
+// DuelRecord is a player's head-to-head record against one opponent.
+type DuelRecord struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+}
+
+// String formats a record as wins-losses, with draws if there were any.
+func (r DuelRecord) String() string {
+	if r.Draws > 0 {
+		return fmt.Sprintf("%d-%d-%d", r.Wins, r.Losses, r.Draws)
+	}
+	return fmt.Sprintf("%d-%d", r.Wins, r.Losses)
+}
+
+// Includes reports whether player is one of the duelists.
+func (d *Duel) Includes(player string) bool {
+	return player == d.Challenger || player == d.Opponent
+}
+
+// Done reports whether the duel is decided: a player has won a majority of
+// the questions, or all of them have been asked.
+func (d *Duel) Done() bool {
+	return d.Asked >= d.BestOf || d.Wins[d.Challenger] > d.BestOf/2 || d.Wins[d.Opponent] > d.BestOf/2
+}
+
+// Winner returns the player who has won more questions, or "" if they are
+// level.
+func (d *Duel) Winner() string {
+	switch {
+	case d.Wins[d.Challenger] > d.Wins[d.Opponent]:
+		return d.Challenger
+	case d.Wins[d.Opponent] > d.Wins[d.Challenger]:
+		return d.Opponent
+	}
+	return ""
+}
+
+// Score formats the duel's score, e.g. "alice 2, bob 1".
+func (d *Duel) Score() string {
+	return fmt.Sprintf("%s %d, %s %d", d.Challenger, d.Wins[d.Challenger], d.Opponent, d.Wins[d.Opponent])
+}
+
+func (d *Duel) clone() *Duel {
+	c := *d
+	c.Wins = make(map[string]int, len(d.Wins))
+	for player, wins := range d.Wins {
+		c.Wins[player] = wins
+	}
+	return &c
+}
+
+// Challenge challenges opponent to a duel of bestOf questions. An open
+// challenge that has not been accepted in time is replaced.
+func (g *Game) Challenge(challenger, opponent string, bestOf int) error {
+	if bestOf < 1 || bestOf > maxDuelLength || bestOf%2 == 0 {
+		return fmt.Errorf("a duel must be best of an odd number of questions up to %d", maxDuelLength)
+	}
+	if strings.EqualFold(challenger, opponent) {
+		return errors.New("you can't duel yourself")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.duel != nil && (g.duel.Accepted || time.Since(g.duel.ChallengedAt) < ChallengeTimeout) {
+		if g.duel.Accepted {
+			return errors.New("a duel is already under way")
+		}
+		return fmt.Errorf("%s has already challenged %s", g.duel.Challenger, g.duel.Opponent)
+	}
+	if g.match != nil {
+		return errors.New("a match is in progress")
+	}
+	g.duel = &Duel{
+		Challenger:   challenger,
+		Opponent:     opponent,
+		BestOf:       bestOf,
+		Wins:         make(map[string]int),
+		ChallengedAt: time.Now(),
+	}
+	return nil
+}
+
+// AcceptDuel starts the duel opponent was challenged to. Continuous play is
+// stopped for the duel; the question that was open, if any, is closed and
+// returned so its answer can be revealed.
+func (g *Game) AcceptDuel(opponent string) (*Duel, *question.Question, error) {
+	g.mu.Lock()
+	d := g.duel
+	if d == nil || d.Accepted || !strings.EqualFold(d.Opponent, opponent) || time.Since(d.ChallengedAt) >= ChallengeTimeout {
+		g.mu.Unlock()
+		return nil, nil, errors.New("nobody has challenged you")
+	}
+	if g.match != nil {
+		g.mu.Unlock()
+		return nil, nil, errors.New("a match is in progress")
+	}
+	d.Opponent = opponent
+	d.Accepted = true
+	g.IsPlaying = false
+	interrupted := g.CurrentQuestion
+	winner, losers := g.clearCurrentQuestion()
+	accepted := d.clone()
+	g.mu.Unlock()
+	g.rateQuestion(winner, losers)
+	return accepted, interrupted, nil
+}
+
+// DeclineDuel withdraws the open challenge player made or turns down the
+// one they received, and returns it.
+func (g *Game) DeclineDuel(player string) (*Duel, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	d := g.duel
+	if d == nil || d.Accepted || !d.Includes(player) || time.Since(d.ChallengedAt) >= ChallengeTimeout {
+		return nil, errors.New("there is no challenge to decline")
+	}
+	g.duel = nil
+	return d, nil
+}
+
+// CurrentDuel returns a copy of the duel under way or challenge open, or nil.
+func (g *Game) CurrentDuel() *Duel {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.duel != nil && !g.duel.Accepted && time.Since(g.duel.ChallengedAt) >= ChallengeTimeout {
+		g.duel = nil // The challenge expired
+	}
+	if g.duel == nil {
+		return nil
+	}
+	return g.duel.clone()
+}
+
+// Dueling reports whether a duel is under way.
+func (g *Game) Dueling() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.duel != nil && g.duel.Accepted
+}
+
+// WinDuelQuestion credits the current duel question to player and returns
+// the duel as it stands.
+func (g *Game) WinDuelQuestion(player string) *Duel {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.duel == nil || !g.duel.Accepted {
+		return nil
+	}
+	g.duel.Wins[player]++
+	return g.duel.clone()
+}
+
+// EndDuel ends the duel under way and returns it. A completed duel goes on
+// both players' head-to-head records; a stopped one does not. It returns nil
+// if no duel is under way.
+func (g *Game) EndDuel(completed bool) *Duel {
+	g.mu.Lock()
+	d := g.duel
+	if d == nil || !d.Accepted {
+		g.mu.Unlock()
+		return nil
+	}
+	g.duel = nil
+	g.mu.Unlock()
+	if completed {
+		g.recordDuel(d)
+	}
+	return d
+}
+
+// HeadToHead returns player's duel records, by opponent.
+func (g *Game) HeadToHead(player string) map[string]DuelRecord {
+	g.historyMu.Lock()
+	defer g.historyMu.Unlock()
+	return g.loadDuelRecords()[player]
+}
+
+// recordDuel adds the outcome of a finished duel to both players' records.
+func (g *Game) recordDuel(d *Duel) {
+	g.historyMu.Lock()
+	defer g.historyMu.Unlock()
+	records := g.loadDuelRecords()
+	if records == nil {
+		records = make(map[string]map[string]DuelRecord)
+	}
+	update := func(player, opponent string, change func(*DuelRecord)) {
+		if records[player] == nil {
+			records[player] = make(map[string]DuelRecord)
+		}
+		r := records[player][opponent]
+		change(&r)
+		records[player][opponent] = r
+	}
+	if winner := d.Winner(); winner == "" {
+		update(d.Challenger, d.Opponent, func(r *DuelRecord) { r.Draws++ })
+		update(d.Opponent, d.Challenger, func(r *DuelRecord) { r.Draws++ })
+	} else {
+		loser := d.Opponent
+		if winner == d.Opponent {
+			loser = d.Challenger
+		}
+		update(winner, loser, func(r *DuelRecord) { r.Wins++ })
+		update(loser, winner, func(r *DuelRecord) { r.Losses++ })
+	}
+
+	bytes, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		log.Printf("Error marshalling duel records: %v", err)
+		return
+	}
+	if err := writeFileAtomic(channelFile(duelRecordFile, g.GameChannel), bytes); err != nil {
+		log.Printf("Error saving duel records: %v", err)
+	}
+}
+
+// loadDuelRecords reads every player's duel records. It must be called with
+// g.historyMu held.
+func (g *Game) loadDuelRecords() map[string]map[string]DuelRecord {
+	bytes, err := os.ReadFile(channelFile(duelRecordFile, g.GameChannel)) // #nosec G304
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading duel records: %v", err)
+		}
+		return nil
+	}
+	var records map[string]map[string]DuelRecord
+	if err := json.Unmarshal(bytes, &records); err != nil {
+		log.Printf("Error unmarshalling duel records: %v", err)
+		return nil
+	}
+	return records
+}
@@ -0,0 +1,104 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"trebek/internal/question"
+)
+
+func TestDuel(t *testing.T) {
+	dir := t.TempDir()
+	originalScoreboardFile, originalDuelRecordFile := scoreboardFile, duelRecordFile
+	scoreboardFile = filepath.Join(dir, "scoreboard.json")
+	duelRecordFile = filepath.Join(dir, "duels.json")
+	defer func() { scoreboardFile, duelRecordFile = originalScoreboardFile, originalDuelRecordFile }()
+
+	game := NewGame(newMockQuestionSource([]*question.Question{
+		{Category: "RIVERS", Question: "It flows through Cairo", Answer: "the Nile"},
+		{Category: "RIVERS", Question: "It flows through Baghdad", Answer: "the Tigris"},
+		{Category: "RIVERS", Question: "It flows through Vienna", Answer: "the Danube"},
+	}), "#trivia")
+
+	for _, bestOf := range []int{0, 4, 17} {
+		if err := game.Challenge("alice", "bob", bestOf); err == nil {
+			t.Errorf("Expected a best-of-%d duel to be refused", bestOf)
+		}
+	}
+	if err := game.Challenge("alice", "Alice", 3); err == nil {
+		t.Error("Expected a duel against yourself to be refused")
+	}
+	if err := game.Challenge("alice", "bob", 3); err != nil {
+		t.Fatalf("Challenge failed: %v", err)
+	}
+	if err := game.Challenge("carol", "dave", 3); err == nil {
+		t.Error("Expected a second challenge to be refused while one is open")
+	}
+	if _, _, err := game.AcceptDuel("carol"); err == nil {
+		t.Error("Expected only the challenged player to accept")
+	}
+
+	// Accepting stops continuous play and closes its question
+	game.SetPlaying(true)
+	asked := game.StartRound()
+	d, interrupted, err := game.AcceptDuel("Bob")
+	if err != nil {
+		t.Fatalf("AcceptDuel failed: %v", err)
+	}
+	if interrupted != asked || game.GetPlaying() || game.GetCurrentQuestion() != nil || !game.Dueling() {
+		t.Error("Expected the duel to take over from continuous play")
+	}
+	if d.Opponent != "Bob" {
+		t.Errorf("Expected the opponent's nick as they typed it, got %q", d.Opponent)
+	}
+	if err := game.StartMatch(3, "carol", false); err == nil {
+		t.Error("Expected no match to start during a duel")
+	}
+
+	game.StartRound()
+	game.WinDuelQuestion("alice")
+	game.StartRound()
+	if d := game.WinDuelQuestion("alice"); !d.Done() || d.Winner() != "alice" || d.Asked != 2 {
+		t.Errorf("Expected alice to have won the duel, got %+v", d)
+	}
+	game.EndDuel(true)
+	if game.CurrentDuel() != nil {
+		t.Error("Expected the duel to be over")
+	}
+	if r := game.HeadToHead("alice")["Bob"]; r != (DuelRecord{Wins: 1}) {
+		t.Errorf("Unexpected record for alice: %+v", r)
+	}
+	if r := game.HeadToHead("Bob")["alice"]; r.String() != "0-1" {
+		t.Errorf("Unexpected record for Bob: %s", r)
+	}
+
+	// A stopped duel is not recorded, and a level one is a draw
+	game.Challenge("Bob", "alice", 1)
+	game.AcceptDuel("alice")
+	game.EndDuel(false)
+	game.Challenge("Bob", "alice", 1)
+	game.AcceptDuel("alice")
+	game.EndDuel(true)
+	if r := game.HeadToHead("alice")["Bob"]; r.String() != "1-0-1" {
+		t.Errorf("Unexpected record after a draw: %s", r)
+	}
+}
+
+func TestDuelChallengeExpires(t *testing.T) {
+	game := NewGame(newMockQuestionSource(nil), "#trivia")
+	game.Challenge("alice", "bob", 3)
+	game.duel.ChallengedAt = time.Now().Add(-ChallengeTimeout)
+	if _, _, err := game.AcceptDuel("bob"); err == nil {
+		t.Error("Expected an expired challenge not to be accepted")
+	}
+	if game.CurrentDuel() != nil {
+		t.Error("Expected the expired challenge to be dropped")
+	}
+	if err := game.Challenge("carol", "dave", 3); err != nil {
+		t.Errorf("Expected a new challenge after the old one expired: %v", err)
+	}
+	if d, err := game.DeclineDuel("dave"); err != nil || d.Challenger != "carol" {
+		t.Errorf("DeclineDuel = %+v, %v", d, err)
+	}
+}
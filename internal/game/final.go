@@ -0,0 +1,252 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+
+	"trebek/internal/question"
+)
+
+// maxFinalSearch caps how many questions are drawn from the source while
+// looking for a Final Jeopardy clue. Regular clues drawn along the way are
+// kept for later rounds.
+const maxFinalSearch = 20
+
+// FinalPhase is the stage a Final Jeopardy round is in.
+type FinalPhase int
+
+const (
+	FinalWagering  FinalPhase = iota // Finalists are placing wagers
+	FinalAnswering                   // The clue is out and responses are collected
+	FinalRevealing                   // Responses are being revealed
+)
+
+// FinalRound is a Final Jeopardy round played at the end of a match.
+// Players with money in the match wager part of it, privately, on a single
+// clue.
+type FinalRound struct {
+	Question *question.Question
+	Phase    FinalPhase
+	Scores   map[string]int    // Match scores of the finalists when the round began
+	Wagers   map[string]int    // Wagers placed; missing finalists wager nothing
+	Answers  map[string]string // Responses, by finalist
+}
+
+// FinalResult is one finalist's outcome.
+type FinalResult struct {
+	Player  string
+	Answer  string // Empty if the finalist did not respond
+	Wager   int
+	Correct bool
+	Before  int // Match score before Final Jeopardy
+	After   int // Match score after Final Jeopardy
+}
+
+// IsFinalClue reports whether q is a Final Jeopardy clue, which unlike
+// regular clues has no dollar value.
+func IsFinalClue(q *question.Question) bool {
+	return q.Value() == 0
+}
+
+// StartFinal begins Final Jeopardy for the match in progress. Every player
+// with a positive match score becomes a finalist.
+func (g *Game) StartFinal() (*FinalRound, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.match == nil {
+		return nil, errors.New("Final Jeopardy is only played at the end of a match")
+	}
+	if g.final != nil {
+		return nil, errors.New("Final Jeopardy is already under way")
+	}
+	scores := make(map[string]int)
+	for player, score := range g.match.Scores {
+		if score > 0 {
+			scores[player] = score
+		}
+	}
+	if len(scores) == 0 {
+		return nil, errors.New("nobody has any money to wager")
+	}
+	q := g.nextFinalClue()
+	if q == nil {
+		return nil, errors.New("no clues are left")
+	}
+	g.final = &FinalRound{
+		Question: q,
+		Phase:    FinalWagering,
+		Scores:   scores,
+		Wagers:   make(map[string]int),
+		Answers:  make(map[string]string),
+	}
+	return g.final.clone(), nil
+}
+
+// nextFinalClue takes a Final Jeopardy clue from the question buffer or, failing
+// that, from the question source. If none turns up, the next regular clue is
+// played as Final Jeopardy instead. It must be called with g.mu held.
+func (g *Game) nextFinalClue() *question.Question {
+	g.bufferMu.Lock()
+	defer g.bufferMu.Unlock()
+	for i, q := range g.questionBuffer {
+		if IsFinalClue(q) {
+			g.questionBuffer = append(g.questionBuffer[:i], g.questionBuffer[i+1:]...)
+			return q
+		}
+	}
+	for range maxFinalSearch {
+		q, err := g.questionSource.Next()
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("Error fetching next question: %v", err)
+			}
+			break
+		}
+		if IsFinalClue(q) {
+			return q
+		}
+		g.questionBuffer = append(g.questionBuffer, q)
+	}
+	if len(g.questionBuffer) == 0 {
+		return nil
+	}
+	q := g.questionBuffer[0]
+	g.questionBuffer = g.questionBuffer[1:]
+	return q
+}
+
+// CurrentFinal returns a copy of the Final Jeopardy round under way, or nil.
+func (g *Game) CurrentFinal() *FinalRound {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.final == nil {
+		return nil
+	}
+	return g.final.clone()
+}
+
+// IsFinalist reports whether player takes part in the Final Jeopardy round
+// under way and may still wager or respond.
+func (g *Game) IsFinalist(player string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.final == nil || g.final.Phase == FinalRevealing {
+		return false
+	}
+	_, ok := g.final.Scores[player]
+	return ok
+}
+
+// PlaceWager records a finalist's wager, which may be changed until the
+// wagers close. It can be anything from nothing to the finalist's match score.
+func (g *Game) PlaceWager(player string, amount int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.final == nil || g.final.Phase != FinalWagering {
+		return errors.New("wagers are closed")
+	}
+	score, ok := g.final.Scores[player]
+	if !ok {
+		return errors.New("only players with money in this match can wager")
+	}
+	if amount < 0 || amount > score {
+		return fmt.Errorf("you can wager between %s and %s", FormatDollars(0), FormatDollars(score))
+	}
+	g.final.Wagers[player] = amount
+	return nil
+}
+
+// CloseWagers ends wagering and returns the clue to reveal, or nil if no
+// Final Jeopardy round is waiting for wagers.
+func (g *Game) CloseWagers() *question.Question {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.final == nil || g.final.Phase != FinalWagering {
+		return nil
+	}
+	g.final.Phase = FinalAnswering
+	return g.final.Question
+}
+
+// SubmitFinalAnswer records a finalist's response, replacing any earlier one.
+func (g *Game) SubmitFinalAnswer(player, answer string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.final == nil || g.final.Phase != FinalAnswering {
+		return errors.New("responses are not being collected")
+	}
+	if _, ok := g.final.Scores[player]; !ok {
+		return errors.New("only finalists can respond")
+	}
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return errors.New("your response is empty")
+	}
+	g.final.Answers[player] = answer
+	return nil
+}
+
+// CloseFinal stops collecting responses, judges them and settles the wagers
+// on the match and all-time scores. Final Jeopardy does not count towards
+// the correct answers of the match. Results are ordered from the lowest score
+// before the round to the highest, the order they are revealed in. It returns
+// nil if no Final Jeopardy round is collecting responses.
+func (g *Game) CloseFinal() []FinalResult {
+	g.mu.Lock()
+	if g.final == nil || g.final.Phase != FinalAnswering {
+		g.mu.Unlock()
+		return nil
+	}
+	g.final.Phase = FinalRevealing
+	final := g.final.clone()
+	var results []FinalResult
+	for player, before := range final.Scores {
+		answer := final.Answers[player]
+		results = append(results, FinalResult{
+			Player:  player,
+			Answer:  answer,
+			Wager:   final.Wagers[player],
+			Correct: answer != "" && g.Matcher.Match(final.Question, answer),
+			Before:  before,
+		})
+	}
+	g.mu.Unlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Before != results[j].Before {
+			return results[i].Before < results[j].Before
+		}
+		return results[i].Player < results[j].Player
+	})
+	for i := range results {
+		r := &results[i]
+		change := -r.Wager
+		if r.Correct {
+			change = r.Wager
+		}
+		r.After = r.Before + change
+		g.adjustScore(r.Player, change, ReasonFinal, final.Question.ID())
+	}
+	return results
+}
+
+func (f *FinalRound) clone() *FinalRound {
+	c := *f
+	c.Scores = make(map[string]int, len(f.Scores))
+	for k, v := range f.Scores {
+		c.Scores[k] = v
+	}
+	c.Wagers = make(map[string]int, len(f.Wagers))
+	for k, v := range f.Wagers {
+		c.Wagers[k] = v
+	}
+	c.Answers = make(map[string]string, len(f.Answers))
+	for k, v := range f.Answers {
+		c.Answers[k] = v
+	}
+	return &c
+}
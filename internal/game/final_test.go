@@ -0,0 +1,140 @@
+package game
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"trebek/internal/question"
+)
+
+func TestFinalJeopardy(t *testing.T) {
+	dir := t.TempDir()
+	originalScoreboardFile, originalMatchHistoryFile := scoreboardFile, matchHistoryFile
+	scoreboardFile = filepath.Join(dir, "scoreboard.json")
+	matchHistoryFile = filepath.Join(dir, "matches.json")
+	defer func() { scoreboardFile, matchHistoryFile = originalScoreboardFile, originalMatchHistoryFile }()
+
+	game := NewGame(newMockQuestionSource([]*question.Question{
+		{Category: "RIVERS", Question: "Q1", Answer: "A1", Money: "$200"},
+		{Category: "RIVERS", Question: "Q2", Answer: "A2", Money: "$400"},
+		{Category: "RIVERS", Question: "Q3", Answer: "A3", Money: "$600"},
+		{Category: "RIVERS", Question: "Q4", Answer: "A4", Money: "$800"},
+		{Category: "WORLD CAPITALS", Question: "It's the capital of Australia", Answer: "Canberra", Money: "None"},
+	}), "#trivia")
+
+	if _, err := game.StartFinal(); err == nil {
+		t.Error("Expected Final Jeopardy to need a match")
+	}
+	game.StartMatch(1, "alice", true)
+	game.AwardCorrect("dave", 0)
+	game.Charge("erin", 200, ReasonHint)
+	if _, err := game.StartFinal(); err == nil {
+		t.Error("Expected Final Jeopardy to need a player with money")
+	}
+	game.AwardCorrect("alice", 1000)
+	game.AwardCorrect("bob", 600)
+	game.AwardCorrect("carol", 400)
+
+	final, err := game.StartFinal()
+	if err != nil {
+		t.Fatalf("StartFinal failed: %v", err)
+	}
+	if final.Question.Answer != "Canberra" || len(final.Scores) != 3 {
+		t.Fatalf("Unexpected Final Jeopardy round: %+v", final)
+	}
+	if len(game.questionBuffer) != 4 {
+		t.Errorf("Expected the regular clues to stay in the buffer, got %d", len(game.questionBuffer))
+	}
+	if !game.IsFinalist("bob") || game.IsFinalist("erin") {
+		t.Error("Only players with money should be finalists")
+	}
+
+	if err := game.PlaceWager("alice", 1001); err == nil {
+		t.Error("Expected a wager over the match score to be refused")
+	}
+	if err := game.PlaceWager("erin", 0); err == nil {
+		t.Error("Expected a non-finalist's wager to be refused")
+	}
+	if err := game.SubmitFinalAnswer("alice", "Canberra"); err == nil {
+		t.Error("Expected responses to wait for the clue")
+	}
+	for player, wager := range map[string]int{"alice": 300, "bob": 600, "carol": 100} {
+		if err := game.PlaceWager(player, wager); err != nil {
+			t.Errorf("PlaceWager(%s, %d) failed: %v", player, wager, err)
+		}
+	}
+	game.PlaceWager("alice", 200) // Changed their mind
+
+	if q := game.CloseWagers(); q == nil || q.Answer != "Canberra" {
+		t.Fatalf("Expected the clue when wagers close, got %v", q)
+	}
+	if err := game.PlaceWager("alice", 100); err == nil {
+		t.Error("Expected wagers to be closed")
+	}
+	game.SubmitFinalAnswer("alice", "Sydney")
+	game.SubmitFinalAnswer("alice", "What is Canberra?")
+	game.SubmitFinalAnswer("bob", "Sydney")
+
+	results := game.CloseFinal()
+	expected := []FinalResult{
+		{Player: "carol", Wager: 100, Before: 400, After: 300},
+		{Player: "bob", Answer: "Sydney", Wager: 600, Before: 600, After: 0},
+		{Player: "alice", Answer: "What is Canberra?", Wager: 200, Correct: true, Before: 1000, After: 1200},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %+v", len(expected), results)
+	}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("Result %d = %+v, expected %+v", i, results[i], expected[i])
+		}
+	}
+	if game.CloseFinal() != nil {
+		t.Error("Expected Final Jeopardy to be closed only once")
+	}
+	if m := game.CurrentMatch(); m.Scores["alice"] != 1200 || m.Scores["bob"] != 0 {
+		t.Errorf("Expected wagers to settle on the match scores, got %v", m.Scores)
+	}
+	if score := game.Scoreboard.GetScore("alice"); score != 1200 {
+		t.Errorf("Expected wagers to settle on the scoreboard, got %d", score)
+	}
+	if game.IsFinalist("alice") {
+		t.Error("Finalists should not be able to change anything while responses are revealed")
+	}
+
+	game.EndMatch()
+	if game.CurrentFinal() != nil {
+		t.Error("Expected Final Jeopardy to end with the match")
+	}
+}
+
+func TestFinalJeopardyFallsBackToRegularClue(t *testing.T) {
+	dir := t.TempDir()
+	originalScoreboardFile, originalMatchHistoryFile := scoreboardFile, matchHistoryFile
+	scoreboardFile = filepath.Join(dir, "scoreboard.json")
+	matchHistoryFile = filepath.Join(dir, "matches.json")
+	defer func() { scoreboardFile, matchHistoryFile = originalScoreboardFile, originalMatchHistoryFile }()
+
+	// More regular clues than are searched, with the only Final Jeopardy clue last
+	var questions []*question.Question
+	for i := range maxFinalSearch + 5 {
+		questions = append(questions, &question.Question{Category: "RIVERS", Question: fmt.Sprintf("Q%d", i), Answer: "A", Money: "$200"})
+	}
+	questions = append(questions, &question.Question{Category: "WORLD CAPITALS", Question: "It's the capital of Australia", Answer: "Canberra"})
+	game := NewGame(newMockQuestionSource(questions), "#trivia")
+	game.StartMatch(1, "alice", true)
+	game.AwardCorrect("alice", 1000)
+	buffered := len(game.questionBuffer)
+
+	final, err := game.StartFinal()
+	if err != nil {
+		t.Fatalf("StartFinal failed: %v", err)
+	}
+	if final.Question.Question != "Q0" {
+		t.Errorf("Expected the first regular clue to be played, got %+v", final.Question)
+	}
+	if len(game.questionBuffer) != buffered+maxFinalSearch-1 {
+		t.Errorf("Expected the search to stop after %d clues, got %d buffered", maxFinalSearch, len(game.questionBuffer))
+	}
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"strings"
+	"unicode"
+)
+
+// accentedLetters lists lower-case letters whose canonical decomposition is
+// a base letter followed by combining marks, grouped by that base letter.
+// This stands in for NFD decomposition without pulling in golang.org/x/text.
+var accentedLetters = map[string]string{
+	"a": "àáâãäåāăąǎǟǡǻȁȃȧḁạảấầẩẫậắằẳẵặ",
+	"b": "ḃḅḇ",
+	"c": "çćĉċčḉ",
+	"d": "ďḋḍḏḑḓ",
+	"e": "èéêëēĕėęěȅȇȩḕḗḙḛḝẹẻẽếềểễệ",
+	"f": "ḟ",
+	"g": "ĝğġģǧǵḡ",
+	"h": "ĥȟḣḥḧḩḫẖ",
+	"i": "ìíîïĩīĭįǐȉȋḭḯỉị",
+	"j": "ĵǰ",
+	"k": "ķǩḱḳḵ",
+	"l": "ĺļľḷḹḻḽ",
+	"m": "ḿṁṃ",
+	"n": "ñńņňǹṅṇṉṋ",
+	"o": "òóôõöōŏőơǒǫǭȍȏȫȭȯȱṍṏṑṓọỏốồổỗộớờởỡợ",
+	"p": "ṕṗ",
+	"r": "ŕŗřȑȓṙṛṝṟ",
+	"s": "śŝşšșṡṣṥṧṩ",
+	"t": "ţťțṫṭṯṱẗ",
+	"u": "ùúûüũūŭůűųưǔǖǘǚǜȕȗṳṵṷṹṻụủứừửữự",
+	"v": "ṽṿ",
+	"w": "ŵẁẃẅẇẉẘ",
+	"x": "ẋẍ",
+	"y": "ýÿŷȳẏẙỳỵỷỹ",
+	"z": "źżžẑẓẕ",
+	"α": "ά",
+	"ε": "έ",
+	"η": "ή",
+	"ι": "ΐίϊ",
+	"ο": "ό",
+	"υ": "ΰϋύ",
+	"ω": "ώ",
+}
+
+// ligatures are letters with no decomposition that players type as plain
+// Latin letters: ligatures, and letters with strokes or other marks that
+// Unicode treats as separate letters.
+var ligatures = map[rune]string{
+	'æ': "ae", 'ǣ': "ae", 'ǽ': "ae", 'œ': "oe", 'ß': "ss", 'ĳ': "ij",
+	'ﬀ': "ff", 'ﬁ': "fi", 'ﬂ': "fl", 'ﬃ': "ffi", 'ﬄ': "ffl",
+	'ø': "o", 'ǿ': "o", 'đ': "d", 'ð': "d", 'þ': "th", 'ł': "l", 'ŀ': "l",
+	'ħ': "h", 'ŧ': "t", 'ı': "i", 'ſ': "s", 'ẛ': "s", 'ƒ': "f",
+}
+
+// foldTable maps every accented letter and ligature to its plain form.
+var foldTable = func() map[rune]string {
+	table := make(map[rune]string, len(ligatures)+300)
+	for base, letters := range accentedLetters {
+		for _, r := range letters {
+			table[r] = base
+		}
+	}
+	for r, plain := range ligatures {
+		table[r] = plain
+	}
+	return table
+}()
+
+// foldAccents lowercases s, strips its diacritics and spells out ligatures,
+// so "Dvořák" becomes "dvorak" and "Æsop" becomes "aesop". Combining marks
+// are dropped too, which handles text that arrives already decomposed.
+func foldAccents(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range strings.ToLower(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if plain, ok := foldTable[r]; ok {
+			b.WriteString(plain)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package game
+
+import "testing"
+
+func TestFoldAccents(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Dvořák", "dvorak"},
+		{"Brontë", "bronte"},
+		{"Dvor\u030ca\u0301k", "dvorak"}, // Already decomposed
+		{"Æsop", "aesop"},
+		{"Straße", "strasse"},
+		{"Œuvre", "oeuvre"},
+		{"Søren Kierkegaard", "soren kierkegaard"},
+		{"Łódź", "lodz"},
+		{"Nguyễn", "nguyen"},
+		{"Αθήνα", "αθηνα"},
+		{"東京", "東京"},
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		if got := foldAccents(tt.input); got != tt.expected {
+			t.Errorf("foldAccents(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -1,12 +1,13 @@
 package game
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -17,114 +18,161 @@ import (
 
 var scoreboardFile = "scoreboard.json"
 
-// Scoreboard stores player scores.
+// Scoreboard stores player and team scores. Every change is an event
+// appended to a journal, which is compacted into a snapshot from time to
+// time and replayed on top of it when the scoreboard is loaded.
 type Scoreboard struct {
-	Scores map[string]int `json:"scores"`
-	mu     sync.Mutex
+	teams   map[string]*Team          // Teams, by name
+	scores  map[string]int            // All-time scores, by player
+	periods map[string]map[string]int // Scores made in each period, by period label
+	seq     int64                     // Sequence number of the last event
+	pending int                       // Events journaled since the last snapshot
+	mu      sync.Mutex
+	path    string // Snapshot file; the journal is kept next to it
 }
 
 // NewScoreboard creates a new scoreboard, loading from file if it exists.
 func NewScoreboard() *Scoreboard {
+	return newScoreboardAt(scoreboardFile)
+}
+
+// NewChannelScoreboard creates a scoreboard namespaced to a channel, so every
+// channel keeps its own scores. A pre-existing un-namespaced scoreboard file is
+// adopted by the first channel that asks for one.
+func NewChannelScoreboard(channel string) *Scoreboard {
+	path := channelFile(scoreboardFile, channel)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if _, err := os.Stat(scoreboardFile); err == nil {
+			log.Printf("Migrating %s to %s for channel %s", scoreboardFile, path, channel)
+			if err := os.Rename(scoreboardFile, path); err != nil {
+				log.Printf("Error migrating scoreboard: %v", err)
+			}
+		}
+	}
+	return newScoreboardAt(path)
+}
+
+func newScoreboardAt(path string) *Scoreboard {
 	sb := &Scoreboard{
-		Scores: make(map[string]int),
+		scores:  make(map[string]int),
+		teams:   make(map[string]*Team),
+		periods: make(map[string]map[string]int),
+		path:    path,
 	}
 	sb.load()
 	return sb
 }
 
+// channelFile derives a per-channel file name from base, e.g.
+// "scoreboard.json" and "#Trivia" become "scoreboard-trivia.json".
+func channelFile(base, channel string) string {
+	name := strings.TrimLeft(strings.ToLower(channel), "#&")
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" {
+		return base
+	}
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-" + name + ext
+}
+
 // AddScore adds points to a player's score.
 func (sb *Scoreboard) AddScore(player string, points int) {
+	sb.AddScoreFor(player, points, "", "")
+}
+
+// AddScoreFor adds points to a player's score, recording why, e.g.
+// ReasonCorrect, and the ID of the question they were for, if any. The
+// change also counts towards the daily, weekly, monthly and yearly
+// leaderboards.
+func (sb *Scoreboard) AddScoreFor(player string, points int, reason, questionID string) {
 	sb.mu.Lock()
 	defer sb.mu.Unlock()
-	sb.Scores[player] += points
-	sb.save()
+	sb.record(ScoreEvent{Type: eventScore, Player: player, Delta: points, Reason: reason, Question: questionID})
 }
 
 // GetScore gets a player's score.
 func (sb *Scoreboard) GetScore(player string) int {
 	sb.mu.Lock()
 	defer sb.mu.Unlock()
-	return sb.Scores[player]
+	return sb.scores[player]
 }
 
-// Reset resets the scoreboard.
-func (sb *Scoreboard) Reset() {
+// Scores returns every player's all-time score, by player.
+func (sb *Scoreboard) Scores() map[string]int {
 	sb.mu.Lock()
 	defer sb.mu.Unlock()
-	sb.Scores = make(map[string]int)
-	sb.save()
-}
-
-// save saves the scoreboard to a file.
-func (sb *Scoreboard) save() {
-	bytes, err := json.MarshalIndent(sb.Scores, "", "  ")
-	if err != nil {
-		log.Printf("Error marshalling scoreboard: %v", err)
-		return
-	}
-	err = os.WriteFile(scoreboardFile, bytes, 0600)
-	if err != nil {
-		log.Printf("Error saving scoreboard: %v", err)
+	scores := make(map[string]int, len(sb.scores))
+	for player, score := range sb.scores {
+		scores[player] = score
 	}
+	return scores
 }
 
-// load loads the scoreboard from a file.
-func (sb *Scoreboard) load() {
-	bytes, err := os.ReadFile(scoreboardFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			log.Printf("Scoreboard file %s not found, starting new.", scoreboardFile)
-			return
-		}
-		log.Printf("Error reading scoreboard file: %v", err)
-		return
-	}
-	err = json.Unmarshal(bytes, &sb.Scores)
-	if err != nil {
-		log.Printf("Error unmarshalling scoreboard: %v", err)
-	}
+// Reset resets the scoreboard. Teams keep their members but start over
+// from zero. The leaderboards of past and current periods are kept.
+func (sb *Scoreboard) Reset() {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	sb.record(ScoreEvent{Type: eventReset})
 }
 
-// Game represents the trivia game state.
-const (
-	HintCost = 50 // Points subtracted per hint
-	MaxHints = 3  // Maximum hints per question
-)
-
 // Game represents the trivia game state.
 type Game struct {
-	questionSource    question.QuestionSource // Source for new questions
-	questionBuffer    []*question.Question    // Buffer of upcoming questions
-	bufferMu          sync.Mutex              // Mutex for questionBuffer
-	CurrentQuestion   *question.Question
-	Scoreboard        *Scoreboard
-	mu                sync.Mutex
-	rand              *rand.Rand
-	hintCount         int    // Number of hints given for the current question
-	hintMask          []rune // Current state of the masked hint
-	IsPlaying         bool   // True if continuous trivia is active
-	GameChannel       string // The IRC channel where the game is played
-	QuestionTimer     *time.Timer
-	AnswerGiven       chan bool       // Channel to signal that an answer was given
-	nextVotes         map[string]bool // Users who voted to skip
-	NextVoteThreshold int             // Number of votes required to skip
+	questionSource  question.QuestionSource // Source for new questions
+	questionBuffer  []*question.Question    // Buffer of upcoming questions
+	bufferMu        sync.Mutex              // Mutex for questionBuffer
+	CurrentQuestion *question.Question
+	Scoreboard      *Scoreboard
+	Ratings         *Ratings
+	mu              sync.Mutex
+	rand            *rand.Rand
+	hintCount       int    // Number of hints given for the current question
+	hintMask        []rune // Current state of the masked hint
+	IsPlaying       bool   // True if continuous trivia is active
+	paused          bool   // True while the bot is disconnected from IRC
+	GameChannel     string // The IRC channel where the game is played
+	QuestionTimer   *time.Timer
+	AnswerGiven     chan bool       // Channel to signal that an answer was given
+	nextVotes       map[string]bool // Users who voted to skip
+	Matcher         Matcher         // Decides whether an answer is correct
+	settings        Settings
+	overrides       map[string]string // Settings changed at runtime, by key
+	settingsPath    string            // File the overrides are persisted to
+	match           *Match            // Match in progress, if any
+	final           *FinalRound       // Final Jeopardy round of the match, if under way
+	dailyDouble     *DailyDouble      // Set if the current question is a Daily Double
+	teamAnswers     map[string]bool   // Teams that scored on the current question while its team window is open
+	duel            *Duel             // Duel under way or challenge open, if any
+	attempts        map[string]bool   // Players who attempted the current question
+	questionWinner  string            // First player to answer the current question correctly
+	historyMu       sync.Mutex        // Serializes access to the match history and duel record files
 }
 
 // NewGame creates a new game instance.
 func NewGame(qs question.QuestionSource, channel string) *Game {
 	source := rand.NewSource(time.Now().UnixNano())
 	g := &Game{
-		questionSource:    qs,
-		questionBuffer:    make([]*question.Question, 0, 3), // Initialize buffer with capacity
-		Scoreboard:        NewScoreboard(),
-		rand:              rand.New(source), // #nosec G404
-		hintMask:          []rune{},
-		IsPlaying:         false,
-		GameChannel:       channel,
-		AnswerGiven:       make(chan bool),
-		nextVotes:         make(map[string]bool),
-		NextVoteThreshold: 3, // Default: 3 votes to skip
+		questionSource: qs,
+		questionBuffer: make([]*question.Question, 0, 3), // Initialize buffer with capacity
+		Scoreboard:     NewChannelScoreboard(channel),
+		Ratings:        NewChannelRatings(channel),
+		rand:           rand.New(source), // #nosec G404
+		hintMask:       []rune{},
+		IsPlaying:      false,
+		GameChannel:    channel,
+		AnswerGiven:    make(chan bool),
+		nextVotes:      make(map[string]bool),
+		Matcher:        NewFuzzyMatcher(StrictnessNormal),
+		settings:       DefaultSettings(),
+		overrides:      make(map[string]string),
+		settingsPath:   channelFile(settingsFile, channel),
 	}
+	g.loadSettings()
 	g.fillQuestionBuffer() // Fill buffer initially
 	return g
 }
@@ -182,6 +230,15 @@ func (g *Game) StartRound() *question.Question {
 	g.bufferMu.Unlock() // Release lock before launching goroutine
 
 	g.CurrentQuestion = q
+	g.attempts = nil
+	g.questionWinner = ""
+	if g.match != nil {
+		g.match.Asked++
+	}
+	if g.duel != nil && g.duel.Accepted {
+		g.duel.Asked++
+	}
+	g.rollDailyDouble(q)
 
 	// Replenish buffer in a separate goroutine after question is taken
 	// This ensures the main thread isn't blocked and the buffer is topped up for next round
@@ -199,19 +256,51 @@ func (g *Game) CheckAnswer(answer string) bool {
 		return false
 	}
 
-	// Normalize both the provided answer and the correct answer for comparison.
-	// This includes converting to lowercase, trimming spaces, and removing non-alphanumeric characters.
-	normalizedAttempt := normalizeAnswer(answer)
-	normalizedCorrect := normalizeAnswer(g.CurrentQuestion.Answer)
+	return g.Matcher.Match(g.CurrentQuestion, answer)
+}
+
+// ClueValue returns the dollars a correct answer to q is worth.
+func (g *Game) ClueValue(q *question.Question) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.clueValue(q)
+}
+
+// clueValue is ClueValue for callers that hold g.mu.
+func (g *Game) clueValue(q *question.Question) int {
+	if value := q.Value(); value > 0 {
+		return value
+	}
+	return g.settings.DefaultClueValue
+}
 
-	// Perform a simple equality check after normalization.
-	return normalizedAttempt == normalizedCorrect
+// HintCost returns the dollars a hint for q costs.
+func (g *Game) HintCost(q *question.Question) int {
+	return g.ClueValue(q) * g.Settings().HintPenalty / 100
 }
 
-// normalizeAnswer converts the input string to lowercase, trims spaces, and removes
-// all non-alphanumeric characters. This helps in robust answer matching.
+// FormatDollars formats a score as dollars, e.g. "$1,200" or "-$400".
+func FormatDollars(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.Itoa(amount)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return sign + "$" + b.String()
+}
+
+// normalizeAnswer converts the input string to lowercase, folds accents, trims spaces,
+// and removes all non-alphanumeric characters. This helps in robust answer matching.
 func normalizeAnswer(s string) string {
-	s = strings.ToLower(s)
+	s = foldAccents(s)
 	s = strings.TrimSpace(s)
 
 	// Use strings.Builder to efficiently build the new string without non-alphanumeric characters.
@@ -232,17 +321,73 @@ func (g *Game) GetCurrentQuestion() *question.Question {
 	return g.CurrentQuestion
 }
 
-// ClearCurrentQuestion clears the current question after it's answered or timed out.
+// ClearCurrentQuestion clears the current question after it's answered or
+// timed out, and rates it.
 func (g *Game) ClearCurrentQuestion() {
 	g.mu.Lock()
-	defer g.mu.Unlock()
+	winner, losers := g.clearCurrentQuestion()
+	g.mu.Unlock()
+	g.rateQuestion(winner, losers)
+}
+
+// ClaimAnswer checks a player's answer to the current question, like Attempt.
+// The first correct answer claims the question: it is closed, rated and
+// returned with true, so neither another answer nor the question's timer can
+// close it again. A wrong answer returns the question, still open, and false.
+// An answer that comes after the question closed returns nil.
+func (g *Game) ClaimAnswer(player, answer string) (*question.Question, bool) {
+	g.mu.Lock()
+	q := g.CurrentQuestion
+	if q == nil {
+		g.mu.Unlock()
+		return nil, false
+	}
+	if !g.attempt(player, answer) {
+		g.mu.Unlock()
+		return q, false
+	}
+	winner, losers := g.clearCurrentQuestion()
+	g.mu.Unlock()
+	g.rateQuestion(winner, losers)
+	return q, true
+}
+
+// CloseQuestion closes q if it is still the current question, e.g. when its
+// time runs out, and reports whether it did. It also returns the Daily Double
+// q was, if any, as it stood when q closed.
+func (g *Game) CloseQuestion(q *question.Question) (DailyDouble, bool) {
+	g.mu.Lock()
+	if q == nil || g.CurrentQuestion != q {
+		g.mu.Unlock()
+		return DailyDouble{}, false
+	}
+	var dd DailyDouble
+	if g.dailyDouble != nil {
+		dd = *g.dailyDouble
+	}
+	winner, losers := g.clearCurrentQuestion()
+	g.mu.Unlock()
+	g.rateQuestion(winner, losers)
+	return dd, true
+}
+
+// clearCurrentQuestion does the work of ClearCurrentQuestion. It returns the
+// question's result for the caller to rate once g.mu is released, so the
+// ratings are not saved under the game lock. It must be called with g.mu held.
+func (g *Game) clearCurrentQuestion() (winner string, losers []string) {
+	if g.CurrentQuestion != nil {
+		winner, losers = g.questionResult()
+	}
 	g.CurrentQuestion = nil
 	g.hintCount = 0
 	g.hintMask = []rune{}
 	g.nextVotes = make(map[string]bool) // Reset votes for new question
+	g.dailyDouble = nil
+	g.teamAnswers = nil
 	if g.QuestionTimer != nil {
 		g.QuestionTimer.Stop()
 	}
+	return winner, losers
 }
 
 // SetPlaying sets the game's playing state.
@@ -259,6 +404,31 @@ func (g *Game) GetPlaying() bool {
 	return g.IsPlaying
 }
 
+// Pause suspends the game while the bot cannot talk to the channel.
+// The question timer is stopped so the current question is kept for Resume.
+func (g *Game) Pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.paused = true
+	if g.QuestionTimer != nil {
+		g.QuestionTimer.Stop()
+	}
+}
+
+// Resume lifts a previous Pause.
+func (g *Game) Resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.paused = false
+}
+
+// IsPaused reports whether the game is paused.
+func (g *Game) IsPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
 // GetHint generates and returns a hint for the current question.
 // It returns the hint string and a boolean indicating if a hint was given.
 func (g *Game) GetHint() (string, bool) {
@@ -268,8 +438,8 @@ func (g *Game) GetHint() (string, bool) {
 	if g.CurrentQuestion == nil {
 		return "No question is currently active.", false
 	}
-	if g.hintCount >= MaxHints {
-		return fmt.Sprintf("Maximum hints (%d) reached for this question.", MaxHints), false
+	if g.hintCount >= g.settings.MaxHints {
+		return fmt.Sprintf("Maximum hints (%d) reached for this question.", g.settings.MaxHints), false
 	}
 
 	answer := []rune(strings.TrimSpace(g.CurrentQuestion.Answer))
@@ -322,14 +492,14 @@ func (g *Game) AddNextVote(user string) (int, int, bool) {
 	}
 
 	if _, exists := g.nextVotes[user]; exists {
-		return len(g.nextVotes), g.NextVoteThreshold, false // User already voted
+		return len(g.nextVotes), g.settings.SkipVotes, false // User already voted
 	}
 
 	g.nextVotes[user] = true
 	currentVotes := len(g.nextVotes)
 
-	if currentVotes >= g.NextVoteThreshold {
-		return currentVotes, g.NextVoteThreshold, true // Threshold reached
+	if currentVotes >= g.settings.SkipVotes {
+		return currentVotes, g.settings.SkipVotes, true // Threshold reached
 	}
-	return currentVotes, g.NextVoteThreshold, false
+	return currentVotes, g.settings.SkipVotes, false
 }
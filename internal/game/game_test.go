@@ -3,6 +3,7 @@ package game
 import (
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -35,7 +36,7 @@ func (m *mockQuestionSource) Close() error {
 
 // Helper function to create a temporary scoreboard file for testing
 func createTempScoreboardFile(t *testing.T, content string) string {
-	tmpfile, err := os.CreateTemp("", "scoreboard_test_*.json")
+	tmpfile, err := os.CreateTemp(t.TempDir(), "scoreboard_test_*.json") // Keeps the event log with it
 	if err != nil {
 		t.Fatalf("Failed to create temp file: %v", err)
 	}
@@ -64,8 +65,8 @@ func TestNewScoreboard(t *testing.T) {
 	if sb == nil {
 		t.Fatal("NewScoreboard returned nil")
 	}
-	if len(sb.Scores) != 0 {
-		t.Errorf("Expected empty scoreboard, got %v", sb.Scores)
+	if len(sb.Scores()) != 0 {
+		t.Errorf("Expected empty scoreboard, got %v", sb.Scores())
 	}
 
 	// Test with existing file
@@ -78,8 +79,8 @@ func TestNewScoreboard(t *testing.T) {
 	if sb == nil {
 		t.Fatal("NewScoreboard returned nil for existing file")
 	}
-	if sb.Scores["player1"] != 10 || sb.Scores["player2"] != 20 {
-		t.Errorf("Expected scores to be loaded, got %v", sb.Scores)
+	if sb.GetScore("player1") != 10 || sb.GetScore("player2") != 20 {
+		t.Errorf("Expected scores to be loaded, got %v", sb.Scores())
 	}
 }
 
@@ -108,8 +109,8 @@ func TestScoreboardAddGetReset(t *testing.T) {
 	}
 
 	sb.Reset()
-	if len(sb.Scores) != 0 {
-		t.Errorf("Expected empty scoreboard after reset, got %v", sb.Scores)
+	if len(sb.Scores()) != 0 {
+		t.Errorf("Expected empty scoreboard after reset, got %v", sb.Scores())
 	}
 	if score := sb.GetScore("playerA"); score != 0 {
 		t.Errorf("Expected score 0 for playerA after reset, got %d", score)
@@ -129,8 +130,59 @@ func TestScoreboardSaveLoad(t *testing.T) {
 
 	// Simulate saving and then loading into a new scoreboard instance
 	sb2 := NewScoreboard() // This will load from the same temp file
-	if sb2.Scores["playerX"] != 10 || sb2.Scores["playerY"] != 20 {
-		t.Errorf("Scores not correctly loaded from file: %v", sb2.Scores)
+	if sb2.GetScore("playerX") != 10 || sb2.GetScore("playerY") != 20 {
+		t.Errorf("Scores not correctly loaded from file: %v", sb2.Scores())
+	}
+}
+
+func TestChannelFile(t *testing.T) {
+	tests := []struct {
+		base, channel, expected string
+	}{
+		{"scoreboard.json", "#trivia", "scoreboard-trivia.json"},
+		{"scoreboard.json", "#Trivia-Hard", "scoreboard-trivia-hard.json"},
+		{"scoreboard.json", "##weird/../chan", "scoreboard-weird____chan.json"},
+		{"data/scoreboard.json", "&local", "data/scoreboard-local.json"},
+		{"scoreboard.json", "#", "scoreboard.json"},
+	}
+	for _, tt := range tests {
+		if got := channelFile(tt.base, tt.channel); got != tt.expected {
+			t.Errorf("channelFile(%q, %q) = %q, expected %q", tt.base, tt.channel, got, tt.expected)
+		}
+	}
+}
+
+func TestNewChannelScoreboard(t *testing.T) {
+	dir := t.TempDir()
+	originalScoreboardFile := scoreboardFile
+	scoreboardFile = filepath.Join(dir, "scoreboard.json")
+	defer func() { scoreboardFile = originalScoreboardFile }()
+
+	// The legacy scoreboard is adopted by the first channel
+	if err := os.WriteFile(scoreboardFile, []byte(`{"alice": 5}`), 0600); err != nil {
+		t.Fatalf("Failed to write legacy scoreboard: %v", err)
+	}
+	trivia := NewChannelScoreboard("#trivia")
+	if trivia.GetScore("alice") != 5 {
+		t.Errorf("Expected migrated score 5 for alice, got %d", trivia.GetScore("alice"))
+	}
+	if _, err := os.Stat(scoreboardFile); !os.IsNotExist(err) {
+		t.Errorf("Expected legacy scoreboard to be moved, stat error: %v", err)
+	}
+
+	// Other channels start empty and keep their scores apart
+	hard := NewChannelScoreboard("#trivia-hard")
+	if len(hard.Scores()) != 0 {
+		t.Errorf("Expected empty scoreboard for #trivia-hard, got %v", hard.Scores())
+	}
+	hard.AddScore("bob", 3)
+	if trivia.GetScore("bob") != 0 {
+		t.Error("Scores leaked between channels")
+	}
+
+	reloaded := NewChannelScoreboard("#Trivia-Hard")
+	if reloaded.GetScore("bob") != 3 {
+		t.Errorf("Expected reloaded score 3 for bob, got %d", reloaded.GetScore("bob"))
 	}
 }
 
@@ -161,8 +213,8 @@ func TestNewGame(t *testing.T) {
 	if game.AnswerGiven == nil {
 		t.Error("AnswerGiven channel not initialized")
 	}
-	if game.NextVoteThreshold != 3 {
-		t.Errorf("Expected NextVoteThreshold 3, got %d", game.NextVoteThreshold)
+	if game.Settings().SkipVotes != DefaultSkipVotes {
+		t.Errorf("Expected %d votes to skip, got %d", DefaultSkipVotes, game.Settings().SkipVotes)
 	}
 }
 
@@ -240,7 +292,7 @@ func TestCheckAnswer(t *testing.T) {
 		{" 4 ", true}, // Trim spaces
 		{"4 ", true},
 		{"4", true},
-		{"four", false},
+		{"four", true}, // Numbers are canonicalized
 		{"", false},
 		{"  ", false},
 	}
@@ -265,10 +317,11 @@ func TestCheckAnswer(t *testing.T) {
 		{"Jordan-River", true},
 		{"Jordan_River", true},
 		{"Jordan  River", true},
-		{"The Jordan River", false}, // Should be false as "The" is not in the answer
-		{"River Jordan", false},     // Order matters with simple equality
-		{"Jordan", false},           // Partial match
-		{"river", false},            // Partial match
+		{"The Jordan River", true}, // Leading articles are ignored
+		{"What is the Jordan River?", true},
+		{"River Jordan", false}, // Order matters with simple equality
+		{"Jordan", false},       // Partial match
+		{"river", false},        // Partial match
 	}
 
 	for _, test := range normalizationTests {
@@ -317,6 +370,42 @@ func TestClearCurrentQuestion(t *testing.T) {
 	// The important part is that the timer is no longer active.
 }
 
+func TestClaimAnswer(t *testing.T) {
+	dir := t.TempDir()
+	originalScoreboardFile, originalRatingsFile := scoreboardFile, ratingsFile
+	scoreboardFile = filepath.Join(dir, "scoreboard.json")
+	ratingsFile = filepath.Join(dir, "ratings.json")
+	defer func() { scoreboardFile, ratingsFile = originalScoreboardFile, originalRatingsFile }()
+
+	game := NewGame(newMockQuestionSource([]*question.Question{
+		{Category: "RIVERS", Question: "It flows through Cairo", Answer: "the Nile"},
+		{Category: "RIVERS", Question: "It flows through Baghdad", Answer: "the Tigris"},
+	}), "#trivia")
+
+	first := game.StartRound()
+	if q, correct := game.ClaimAnswer("bob", "Danube"); q != first || correct {
+		t.Errorf("Expected a wrong answer to leave the question open, got %v, %v", q, correct)
+	}
+	if q, correct := game.ClaimAnswer("alice", first.Answer); q != first || !correct {
+		t.Errorf("Expected alice to claim the question, got %v, %v", q, correct)
+	}
+	if q, _ := game.ClaimAnswer("bob", first.Answer); q != nil {
+		t.Errorf("Expected a late answer to find the question closed, got %v", q)
+	}
+	if _, closed := game.CloseQuestion(first); closed {
+		t.Error("Expected the timer to lose to the claimed answer")
+	}
+
+	// Once time runs out, the question can no longer be claimed
+	second := game.StartRound()
+	if _, closed := game.CloseQuestion(second); !closed {
+		t.Error("Expected the timer to close the question")
+	}
+	if q, correct := game.ClaimAnswer("alice", second.Answer); q != nil || correct {
+		t.Errorf("Expected an answer after time ran out to be ignored, got %v, %v", q, correct)
+	}
+}
+
 func TestSetGetPlaying(t *testing.T) {
 	mockQs := newMockQuestionSource([]*question.Question{})
 	game := NewGame(mockQs, "#test")
@@ -355,14 +444,14 @@ func TestGetHint(t *testing.T) {
 	}
 
 	// Test subsequent hints
-	for i := 0; i < MaxHints-1; i++ {
+	for i := 0; i < DefaultMaxHints-1; i++ {
 		_, given = game.GetHint()
 		if !given {
 			t.Errorf("Expected hint to be given on iteration %d", i+2)
 		}
 	}
-	if game.hintCount != MaxHints {
-		t.Errorf("Expected hintCount %d, got %d", MaxHints, game.hintCount)
+	if game.hintCount != DefaultMaxHints {
+		t.Errorf("Expected hintCount %d, got %d", DefaultMaxHints, game.hintCount)
 	}
 
 	// Test max hints reached
@@ -385,6 +474,50 @@ func TestGetHint(t *testing.T) {
 	}
 }
 
+func TestClueValueAndHintCost(t *testing.T) {
+	game := NewGame(newMockQuestionSource(nil), "#testchannel")
+	tests := []struct {
+		money       string
+		value, hint int
+	}{
+		{"$400", 400, 40},
+		{"$1,200", 1200, 120},
+		{"None", DefaultClueValue, DefaultClueValue / 10},
+		{"", DefaultClueValue, DefaultClueValue / 10},
+	}
+	for _, tt := range tests {
+		q := &question.Question{Money: tt.money}
+		if got := game.ClueValue(q); got != tt.value {
+			t.Errorf("ClueValue(%q) = %d, expected %d", tt.money, got, tt.value)
+		}
+		if got := game.HintCost(q); got != tt.hint {
+			t.Errorf("HintCost(%q) = %d, expected %d", tt.money, got, tt.hint)
+		}
+	}
+
+	game.settings.DefaultClueValue = 2000
+	game.settings.HintPenalty = 25
+	if got := game.HintCost(&question.Question{}); got != 500 {
+		t.Errorf("Expected a configured hint cost of 500, got %d", got)
+	}
+}
+
+func TestFormatDollars(t *testing.T) {
+	tests := map[int]string{
+		0:       "$0",
+		400:     "$400",
+		1200:    "$1,200",
+		-400:    "-$400",
+		1000000: "$1,000,000",
+		-12345:  "-$12,345",
+	}
+	for amount, expected := range tests {
+		if got := FormatDollars(amount); got != expected {
+			t.Errorf("FormatDollars(%d) = %q, expected %q", amount, got, expected)
+		}
+	}
+}
+
 func TestAddNextVote(t *testing.T) {
 	// Test case 1: Basic voting and skipping
 	t.Run("BasicVotingAndSkipping", func(t *testing.T) {
@@ -394,7 +527,7 @@ func TestAddNextVote(t *testing.T) {
 		})
 		game := NewGame(mockQs, "#testchannel")
 		game.StartRound()
-		game.NextVoteThreshold = 2 // Set a low threshold for testing
+		game.settings.SkipVotes = 2 // Set a low threshold for testing
 
 		// First vote
 		currentVotes, threshold, skipped := game.AddNextVote("user1")
@@ -424,7 +557,7 @@ func TestAddNextVote(t *testing.T) {
 		})
 		game := NewGame(mockQs, "#testchannel")
 		game.StartRound() // Start a new question for this test case
-		game.NextVoteThreshold = 2
+		game.settings.SkipVotes = 2
 		game.AddNextVote("userA")
 		currentVotes, threshold, skipped := game.AddNextVote("userA")
 		if skipped {
@@ -449,3 +582,32 @@ func TestAddNextVote(t *testing.T) {
 		}
 	})
 }
+
+func TestPauseResume(t *testing.T) {
+	mockQs := newMockQuestionSource([]*question.Question{
+		{Category: "Test", Question: "Q1", Answer: "A1"},
+	})
+	game := NewGame(mockQs, "#testchannel")
+	game.StartRound()
+
+	fired := make(chan struct{})
+	game.QuestionTimer = time.AfterFunc(20*time.Millisecond, func() { close(fired) })
+
+	game.Pause()
+	if !game.IsPaused() {
+		t.Error("Expected game to be paused")
+	}
+	select {
+	case <-fired:
+		t.Error("Question timer should have been stopped by Pause")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if game.GetCurrentQuestion() == nil {
+		t.Error("Pause should keep the current question")
+	}
+
+	game.Resume()
+	if game.IsPaused() {
+		t.Error("Expected game to be resumed")
+	}
+}
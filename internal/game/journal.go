@@ -0,0 +1,300 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// snapshotEvery is how many events are journaled before the scoreboard is
+// compacted into a new snapshot.
+var snapshotEvery = 500
+
+// snapshotVersion marks snapshots written with a journal. Scoreboard files
+// saved before it have no version.
+const snapshotVersion = 1
+
+// Types of score event.
+const (
+	eventScore      = "score"
+	eventTeamScore  = "team_score"
+	eventCreateTeam = "create_team"
+	eventJoinTeam   = "join_team"
+	eventLeaveTeam  = "leave_team"
+	eventReset      = "reset"
+)
+
+// Reasons a player's score changed.
+const (
+	ReasonCorrect     = "correct"      // A correct answer
+	ReasonHint        = "hint"         // The cost of a hint
+	ReasonDailyDouble = "daily_double" // A Daily Double wager won or lost
+	ReasonFinal       = "final"        // A Final Jeopardy wager won or lost
+)
+
+// ScoreEvent is one change to the scoreboard, as journaled.
+type ScoreEvent struct {
+	Seq      int64     `json:"seq"` // Position in the journal, from 1
+	Time     time.Time `json:"time"`
+	Type     string    `json:"type"`
+	Player   string    `json:"player,omitempty"`
+	Team     string    `json:"team,omitempty"`
+	Delta    int       `json:"delta,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+	Question string    `json:"question,omitempty"` // ID of the question scored on
+}
+
+// snapshot is the scoreboard as of the event numbered Seq.
+type snapshot struct {
+	Version int                       `json:"version"`
+	Seq     int64                     `json:"seq"`
+	Scores  map[string]int            `json:"scores"`
+	Teams   map[string]*Team          `json:"teams,omitempty"`
+	Periods map[string]map[string]int `json:"periods,omitempty"`
+}
+
+// eventsPath derives the journal of a scoreboard file, e.g.
+// "scoreboard-trivia.json" keeps its events in "scoreboard-trivia-events.jsonl".
+func eventsPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "-events.jsonl"
+}
+
+// record journals an event and applies it to the scoreboard. Every
+// snapshotEvery events the journal is compacted. It must be called with
+// sb.mu held.
+func (sb *Scoreboard) record(event ScoreEvent) {
+	sb.seq++
+	event.Seq = sb.seq
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	sb.apply(event)
+	sb.pending++
+	if err := sb.appendEvent(event); err != nil {
+		log.Printf("Error journaling score event: %v", err)
+		sb.pending = snapshotEvery // Don't lose it; snapshot it instead
+	}
+	if sb.pending >= snapshotEvery {
+		sb.compact()
+	}
+}
+
+// apply makes the change an event describes. It must be called with sb.mu
+// held.
+func (sb *Scoreboard) apply(event ScoreEvent) {
+	switch event.Type {
+	case eventScore:
+		sb.scores[event.Player] += event.Delta
+		sb.addToPeriods(event)
+	case eventTeamScore:
+		if team, ok := sb.teams[event.Team]; ok {
+			team.Score += event.Delta
+		}
+	case eventCreateTeam:
+		sb.leave(event.Player)
+		sb.teams[event.Team] = &Team{Members: []string{event.Player}}
+	case eventJoinTeam:
+		sb.leave(event.Player)
+		team, ok := sb.teams[event.Team]
+		if !ok {
+			team = &Team{}
+			sb.teams[event.Team] = team
+		}
+		team.Members = append(team.Members, event.Player)
+	case eventLeaveTeam:
+		sb.leave(event.Player)
+	case eventReset:
+		sb.scores = make(map[string]int)
+		for _, team := range sb.teams {
+			team.Score = 0
+		}
+	default:
+		log.Printf("Skipping score event %d of unknown type %q", event.Seq, event.Type)
+	}
+}
+
+// appendEvent adds an event to the end of the journal and flushes it to disk.
+func (sb *Scoreboard) appendEvent(event ScoreEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(eventsPath(sb.path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// compact writes the scoreboard to a new snapshot and empties the journal.
+// The journal is only emptied once the snapshot is safely on disk; should
+// that not happen, the events stay in the journal to be replayed. It must be
+// called with sb.mu held.
+func (sb *Scoreboard) compact() {
+	data, err := json.MarshalIndent(snapshot{
+		Version: snapshotVersion,
+		Seq:     sb.seq,
+		Scores:  sb.scores,
+		Teams:   sb.teams,
+		Periods: sb.periods,
+	}, "", "  ")
+	if err != nil {
+		log.Printf("Error marshalling scoreboard: %v", err)
+		return
+	}
+	if err := writeFileAtomic(sb.path, data); err != nil {
+		log.Printf("Error saving scoreboard: %v", err)
+		return
+	}
+	// Events up to sb.seq are in the snapshot now, so replay would skip them
+	// even if the journal survived a crash here.
+	if err := os.Remove(eventsPath(sb.path)); err != nil && !os.IsNotExist(err) {
+		log.Printf("Error emptying score journal: %v", err)
+		return
+	}
+	sb.pending = 0
+}
+
+// writeFileAtomic replaces a file with data so that, whatever happens, the
+// file holds either its old contents or all of data: data is written to a
+// temporary file that is flushed to disk and then renamed over the file.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	// Flush the rename too. Not every platform can sync a directory, and the
+	// file is already in place, so failing to is not an error.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// load restores the scoreboard from its snapshot and replays the journal on
+// top of it. A scoreboard file saved before the journal, whether as scores
+// alone or with teams, is migrated to a snapshot.
+func (sb *Scoreboard) load() {
+	snap, err := readSnapshot(sb.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Printf("Scoreboard file %s not found, starting new.", sb.path)
+		} else {
+			log.Printf("Error reading scoreboard file: %v", err)
+		}
+	}
+	if snap.Scores != nil {
+		sb.scores = snap.Scores
+	}
+	if snap.Teams != nil {
+		sb.teams = snap.Teams
+	}
+	if snap.Periods != nil {
+		sb.periods = snap.Periods
+	}
+	sb.seq = snap.Seq
+	sb.replay(snap)
+
+	if err == nil && snap.Version < snapshotVersion {
+		log.Printf("Migrating scoreboard %s to a snapshot and journal", sb.path)
+		sb.compact()
+	}
+}
+
+// readSnapshot reads a scoreboard snapshot in any of the formats it was ever
+// saved in.
+func readSnapshot(path string) (snapshot, error) {
+	var snap snapshot
+	data, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return snap, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return snap, err
+	}
+	if scores, ok := fields["scores"]; ok && strings.HasPrefix(string(scores), "{") {
+		err = json.Unmarshal(data, &snap)
+	} else {
+		err = json.Unmarshal(data, &snap.Scores) // Saved before teams, as scores alone
+	}
+	return snap, err
+}
+
+// replay applies the journaled events that came after the snapshot. Events
+// logged before the journal, which only fed the period leaderboards, count
+// towards them until the scoreboard is migrated. A line torn by a crash
+// while it was being written is cut off, so the next event starts on a line
+// of its own.
+func (sb *Scoreboard) replay(snap snapshot) {
+	path := eventsPath(sb.path)
+	data, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading score journal: %v", err)
+		}
+		return
+	}
+	if end := bytes.LastIndexByte(data, '\n') + 1; end < len(data) {
+		log.Printf("Cutting off torn score event at the end of %s", path)
+		if err := os.Truncate(path, int64(end)); err != nil {
+			log.Printf("Error cutting off torn score event: %v", err)
+		}
+		data = data[:end]
+	}
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var event struct {
+			ScoreEvent
+			Points int `json:"points"` // Logged before the journal
+		}
+		if err := json.Unmarshal(line, &event); err != nil {
+			log.Printf("Skipping bad score event on line %d: %v", i+1, err)
+			continue
+		}
+		switch {
+		case event.Seq == 0 && snap.Version < snapshotVersion:
+			event.Delta = event.Points
+			sb.addToPeriods(event.ScoreEvent)
+		case event.Seq > sb.seq:
+			sb.apply(event.ScoreEvent)
+			sb.seq = event.Seq
+			sb.pending++
+		}
+	}
+}
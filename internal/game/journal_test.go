@@ -0,0 +1,189 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readJournal returns the events in a scoreboard's journal.
+func readJournal(t *testing.T, path string) []ScoreEvent {
+	t.Helper()
+	f, err := os.Open(eventsPath(path))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	defer f.Close()
+	var events []ScoreEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event ScoreEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Bad journal line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestJournalReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scoreboard.json")
+	sb := newScoreboardAt(path)
+	sb.AddScoreFor("alice", 400, ReasonCorrect, "q1")
+	sb.AddScoreFor("bob", -200, ReasonHint, "q2")
+	if err := sb.CreateTeam("Owls", "alice"); err != nil {
+		t.Fatalf("CreateTeam failed: %v", err)
+	}
+	if _, err := sb.JoinTeam("owls", "bob"); err != nil {
+		t.Fatalf("JoinTeam failed: %v", err)
+	}
+	sb.AddTeamScoreFor("Owls", 400, ReasonCorrect, "q1")
+	if _, err := sb.LeaveTeam("bob"); err != nil {
+		t.Fatalf("LeaveTeam failed: %v", err)
+	}
+
+	events := readJournal(t, path)
+	if len(events) != 6 {
+		t.Fatalf("Expected 6 journaled events, got %d", len(events))
+	}
+	first := events[0]
+	if first.Seq != 1 || first.Type != eventScore || first.Player != "alice" || first.Delta != 400 ||
+		first.Reason != ReasonCorrect || first.Question != "q1" || first.Time.IsZero() {
+		t.Errorf("Unexpected first event: %+v", first)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no snapshot before compaction, got %v", err)
+	}
+
+	reloaded := newScoreboardAt(path)
+	if reloaded.GetScore("alice") != 400 || reloaded.GetScore("bob") != -200 {
+		t.Errorf("Scores not replayed: %v", reloaded.Scores())
+	}
+	if team, ok := reloaded.Team("Owls"); !ok || team.Score != 400 || len(team.Members) != 1 || team.Members[0] != "alice" {
+		t.Errorf("Team not replayed: %+v, %v", team, ok)
+	}
+
+	// Numbering carries on from the replayed events
+	reloaded.AddScore("carol", 100)
+	if events := readJournal(t, path); events[len(events)-1].Seq != 7 {
+		t.Errorf("Expected the next event to be #7, got %+v", events[len(events)-1])
+	}
+}
+
+func TestJournalCompaction(t *testing.T) {
+	defer func(n int) { snapshotEvery = n }(snapshotEvery)
+	snapshotEvery = 3
+	path := filepath.Join(t.TempDir(), "scoreboard.json")
+	sb := newScoreboardAt(path)
+	for i := 0; i < 4; i++ {
+		sb.AddScore("alice", 100)
+	}
+
+	snap, err := readSnapshot(path)
+	if err != nil {
+		t.Fatalf("Failed to read snapshot: %v", err)
+	}
+	if snap.Version != snapshotVersion || snap.Seq != 3 || snap.Scores["alice"] != 300 {
+		t.Errorf("Unexpected snapshot: %+v", snap)
+	}
+	if events := readJournal(t, path); len(events) != 1 || events[0].Seq != 4 {
+		t.Errorf("Expected only event #4 left in the journal, got %+v", events)
+	}
+	if matches, _ := filepath.Glob(path + ".tmp-*"); len(matches) != 0 {
+		t.Errorf("Temporary files left behind: %v", matches)
+	}
+
+	// A journal that outlived its snapshot, as after a crash before it was
+	// emptied, is not counted twice
+	line, _ := json.Marshal(ScoreEvent{Seq: 2, Time: time.Now(), Type: eventScore, Player: "alice", Delta: 100})
+	f, err := os.OpenFile(eventsPath(path), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	f.Write(append(line, '\n'))
+	f.Close()
+
+	reloaded := newScoreboardAt(path)
+	if got := reloaded.GetScore("alice"); got != 400 {
+		t.Errorf("Expected alice to have 400 after replay, got %d", got)
+	}
+	month := PeriodLabel("month", time.Now())
+	if got := reloaded.PeriodScores(month)["alice"]; got != 400 {
+		t.Errorf("Expected alice to have 400 in %s, got %d", month, got)
+	}
+}
+
+func TestJournalTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scoreboard.json")
+	sb := newScoreboardAt(path)
+	sb.AddScore("alice", 200)
+	f, err := os.OpenFile(eventsPath(path), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	f.WriteString(`{"seq":2,"time":"2026-10-16T12:00:00Z","type":"sco`)
+	f.Close()
+
+	reloaded := newScoreboardAt(path)
+	reloaded.AddScore("bob", 300)
+	events := readJournal(t, path)
+	if len(events) != 2 || events[1].Player != "bob" || events[1].Seq != 2 {
+		t.Errorf("Expected the torn event to be replaced by bob's, got %+v", events)
+	}
+	if again := newScoreboardAt(path); again.GetScore("alice") != 200 || again.GetScore("bob") != 300 {
+		t.Errorf("Unexpected scores after the torn event: %v", again.Scores())
+	}
+}
+
+func TestJournalMigratesScoreboard(t *testing.T) {
+	legacy := map[string]string{
+		"scores only": `{"alice": 500, "bob": 200}`,
+		"with teams":  `{"scores": {"alice": 500, "bob": 200}, "teams": {"Owls": {"members": ["alice"], "score": 500}}}`,
+	}
+	for name, content := range legacy {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "scoreboard.json")
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatalf("Failed to write scoreboard: %v", err)
+			}
+			// The period leaderboards were kept in an event log of their own
+			events := `{"time":"2026-09-15T10:00:00Z","player":"alice","points":300}` + "\n" +
+				`{"time":"2026-09-16T10:00:00Z","player":"bob","points":200}` + "\n"
+			if err := os.WriteFile(eventsPath(path), []byte(events), 0600); err != nil {
+				t.Fatalf("Failed to write event log: %v", err)
+			}
+
+			sb := newScoreboardAt(path)
+			if sb.GetScore("alice") != 500 || sb.GetScore("bob") != 200 {
+				t.Errorf("Scores not migrated: %v", sb.Scores())
+			}
+			if scores := sb.PeriodScores("2026-09"); scores["alice"] != 300 || scores["bob"] != 200 {
+				t.Errorf("Period scores not migrated: %v", scores)
+			}
+			snap, err := readSnapshot(path)
+			if err != nil || snap.Version != snapshotVersion || snap.Scores["alice"] != 500 {
+				t.Errorf("Expected a snapshot to be written, got %+v, %v", snap, err)
+			}
+			if _, err := os.Stat(eventsPath(path)); !os.IsNotExist(err) {
+				t.Errorf("Expected the old event log to be folded into the snapshot, got %v", err)
+			}
+
+			sb.AddScore("alice", 100)
+			reloaded := newScoreboardAt(path)
+			if reloaded.GetScore("alice") != 600 || reloaded.PeriodScores("2026-09")["alice"] != 300 {
+				t.Errorf("Unexpected scores after migration: %v, %v", reloaded.Scores(), reloaded.PeriodScores("2026-09"))
+			}
+			if name == "with teams" {
+				if team, ok := reloaded.Team("owls"); !ok || team.Score != 500 {
+					t.Errorf("Team not migrated: %+v, %v", team, ok)
+				}
+			}
+		})
+	}
+}
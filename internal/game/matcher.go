@@ -0,0 +1,237 @@
+package game
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"trebek/internal/question"
+)
+
+// Strictness controls how forgiving answer matching is about typos.
+type Strictness int
+
+const (
+	StrictnessExact   Strictness = iota // Only exact matches after normalization
+	StrictnessStrict                    // About one typo per ten letters
+	StrictnessNormal                    // About one typo per five letters
+	StrictnessLenient                   // About three typos per ten letters
+)
+
+var strictnessNames = []string{"exact", "strict", "normal", "lenient"}
+
+// minSimilarity is the similarity an attempt needs at each strictness.
+var minSimilarity = []float64{1, 0.9, 0.8, 0.7}
+
+// minFuzzyLength is the shortest normalized answer that tolerates typos.
+// Anything shorter must be typed exactly, so "cat" never accepts "car".
+const minFuzzyLength = 4
+
+// String returns the lower-case name of the strictness level.
+func (s Strictness) String() string {
+	if s < StrictnessExact || int(s) >= len(strictnessNames) {
+		return fmt.Sprintf("strictness(%d)", int(s))
+	}
+	return strictnessNames[s]
+}
+
+// ParseStrictness parses a strictness name such as "lenient", ignoring case.
+func ParseStrictness(name string) (Strictness, error) {
+	for i, n := range strictnessNames {
+		if strings.EqualFold(strings.TrimSpace(name), n) {
+			return Strictness(i), nil
+		}
+	}
+	return StrictnessNormal, fmt.Errorf("unknown strictness %q (use %s)", name, strings.Join(strictnessNames, ", "))
+}
+
+// Matcher decides whether an attempt answers a question.
+type Matcher interface {
+	Match(q *question.Question, attempt string) bool
+}
+
+// FuzzyMatcher accepts answers within a typo budget scaled to the answer's
+// length, and surnames alone when the clue is about a person.
+type FuzzyMatcher struct {
+	Strictness Strictness
+}
+
+// NewFuzzyMatcher creates a matcher with the given strictness.
+func NewFuzzyMatcher(strictness Strictness) *FuzzyMatcher {
+	return &FuzzyMatcher{Strictness: strictness}
+}
+
+// Match reports whether attempt answers q.
+func (m *FuzzyMatcher) Match(q *question.Question, attempt string) bool {
+	attempts := normalizedForms(canonicalAttempt(attempt), true)
+	if len(attempts) == 0 {
+		return false
+	}
+	person := describesPerson(q)
+	for _, variant := range answerVariants(q.Answer) {
+		for _, correct := range normalizedForms(variant, false) {
+			for _, a := range attempts {
+				if m.compare(a, correct) {
+					return true
+				}
+			}
+		}
+		if !person {
+			continue
+		}
+		for _, surname := range surnames(variant) {
+			for _, a := range attempts {
+				if m.compare(a, numberForm{surname, false}) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// numberForm is a normalized answer or attempt. Forms that still spell out
+// Roman numerals must match exactly, since "viii" is one typo from "vii".
+type numberForm struct {
+	text      string
+	exactOnly bool
+}
+
+func normalizedForms(s string, anyCase bool) []numberForm {
+	forms := numberForms(s, anyCase)
+	var normalized []numberForm
+	for i, form := range forms {
+		if text := normalizeAnswer(form); text != "" {
+			normalized = append(normalized, numberForm{text, i < len(forms)-1})
+		}
+	}
+	return normalized
+}
+
+func (m *FuzzyMatcher) compare(attempt, correct numberForm) bool {
+	if attempt.exactOnly || correct.exactOnly {
+		return attempt.text == correct.text
+	}
+	return m.similar(attempt.text, correct.text)
+}
+
+// similar compares two normalized answers.
+func (m *FuzzyMatcher) similar(attempt, correct string) bool {
+	if attempt == correct {
+		return true
+	}
+	if m.Strictness <= StrictnessExact || int(m.Strictness) >= len(minSimilarity) {
+		return false
+	}
+	// Numbers must be right: "1812" is not a typo of "1813".
+	if digitsOf(attempt) != digitsOf(correct) {
+		return false
+	}
+	a, c := []rune(attempt), []rune(correct)
+	if len(c) < minFuzzyLength {
+		return false
+	}
+	return similarity(a, c) >= minSimilarity[m.Strictness]
+}
+
+// similarity scales the edit distance to the length of the longer string:
+// 1 means identical, 0 means nothing in common.
+func similarity(a, b []rune) float64 {
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(damerauLevenshtein(a, b))/float64(longest)
+}
+
+// damerauLevenshtein returns the optimal string alignment distance: the
+// number of insertions, deletions, substitutions and adjacent transpositions
+// needed to turn a into b.
+func damerauLevenshtein(a, b []rune) int {
+	// Three rolling rows: two back (for transpositions), previous and current.
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
+
+func digitsOf(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// personCue spots clues whose subject is a person, e.g. "He wrote...",
+// "This author..." or "This Russian composed...". Merely mentioning someone,
+// as in "...where his father was born", is not enough.
+var personCue = regexp.MustCompile(`(?i)\b(he|she|this (man|woman|author|writer|poet|painter|artist|composer|singer|actor|actress|president|king|queen|emperor|explorer|inventor|scientist|philosopher|playwright|novelist|general|leader|pope|saint|star|comedian)|this \w+ (composed|painted|sculpted|wrote|starred))\b`)
+
+// placeCue spots clues that ask for a place or thing, e.g. "This city,
+// where he was born...", even though a person is their subject too.
+var placeCue = regexp.MustCompile(`(?i)\b(this|these) (\w+ )?(city|cities|town|capital|country|nation|state|province|island|river|lake|mountain|planet|place|company|band|team|ship|building|war|film|movie|book|novel|play|opera|song|album|show)\b`)
+
+// nameParticles may appear in lower case inside a personal name.
+var nameParticles = map[string]bool{
+	"van": true, "von": true, "de": true, "da": true, "di": true, "du": true,
+	"del": true, "della": true, "der": true, "den": true, "la": true, "le": true,
+	"al": true, "bin": true, "ibn": true, "y": true,
+}
+
+// describesPerson reports whether the clue looks like it is about a person.
+func describesPerson(q *question.Question) bool {
+	if placeCue.MatchString(q.Question) {
+		return false
+	}
+	return personCue.MatchString(q.Question) || personCue.MatchString(q.Category)
+}
+
+// surnames returns the normalized forms a player may give instead of a
+// person's full name: the last name on its own and, for names like
+// "Ludwig van Beethoven", with its particles.
+func surnames(name string) []string {
+	words := strings.Fields(name)
+	if len(words) < 2 || len(words) > 4 {
+		return nil
+	}
+	for _, w := range words {
+		r := []rune(w)
+		if !unicode.IsUpper(r[0]) && !nameParticles[strings.ToLower(w)] {
+			return nil // Not capitalized like a name
+		}
+	}
+
+	last := len(words) - 1
+	forms := []string{normalizeAnswer(words[last])}
+	first := last
+	for first > 1 && nameParticles[strings.ToLower(words[first-1])] {
+		first--
+	}
+	if first < last {
+		forms = append(forms, normalizeAnswer(strings.Join(words[first:], " ")))
+	}
+	if len([]rune(forms[0])) < minFuzzyLength-1 {
+		return nil // Too short to identify anyone, e.g. "Li"
+	}
+	return forms
+}
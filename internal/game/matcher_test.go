@@ -0,0 +1,118 @@
+package game
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"trebek/internal/question"
+)
+
+// corpusEntry is a real clue with answers players should and should not get credit for.
+type corpusEntry struct {
+	question.Question
+	Accept []string `json:"accept"`
+	Reject []string `json:"reject"`
+}
+
+func loadCorpus(t *testing.T) []corpusEntry {
+	t.Helper()
+	data, err := os.ReadFile("testdata/answers.json")
+	if err != nil {
+		t.Fatalf("Failed to read corpus: %v", err)
+	}
+	var corpus []corpusEntry
+	if err := json.Unmarshal(data, &corpus); err != nil {
+		t.Fatalf("Failed to parse corpus: %v", err)
+	}
+	return corpus
+}
+
+func TestMatcherCorpus(t *testing.T) {
+	m := NewFuzzyMatcher(StrictnessNormal)
+	for _, entry := range loadCorpus(t) {
+		for _, attempt := range entry.Accept {
+			if !m.Match(&entry.Question, attempt) {
+				t.Errorf("%q should be accepted for %q (%s)", attempt, entry.Answer, entry.Category)
+			}
+		}
+		for _, attempt := range entry.Reject {
+			if m.Match(&entry.Question, attempt) {
+				t.Errorf("%q should be rejected for %q (%s)", attempt, entry.Answer, entry.Category)
+			}
+		}
+	}
+}
+
+func TestMatcherStrictness(t *testing.T) {
+	q := &question.Question{Category: "U.S. RIVERS", Question: "Its source is Lake Itasca", Answer: "Mississippi"}
+	tests := []struct {
+		attempt  string
+		accepted []Strictness
+	}{
+		{"Mississippi", []Strictness{StrictnessExact, StrictnessStrict, StrictnessNormal, StrictnessLenient}},
+		{"Mississipi", []Strictness{StrictnessStrict, StrictnessNormal, StrictnessLenient}},
+		{"Misisipi", []Strictness{StrictnessLenient}},
+		{"Missouri", nil},
+	}
+	for _, tt := range tests {
+		accepted := make(map[Strictness]bool)
+		for _, s := range tt.accepted {
+			accepted[s] = true
+		}
+		for s := StrictnessExact; s <= StrictnessLenient; s++ {
+			if got := NewFuzzyMatcher(s).Match(q, tt.attempt); got != accepted[s] {
+				t.Errorf("Match(%q) at %s = %v, expected %v", tt.attempt, s, got, accepted[s])
+			}
+		}
+	}
+}
+
+func TestSurnamesNeedAPersonClue(t *testing.T) {
+	m := NewFuzzyMatcher(StrictnessNormal)
+	place := &question.Question{Category: "NATIONAL PARKS", Question: "This Wyoming national park is named for its tallest peak", Answer: "Grand Teton"}
+	if m.Match(place, "Teton") {
+		t.Error("Last word of a place should not be accepted on its own")
+	}
+	person := &question.Question{Category: "ASTRONOMERS", Question: "She discovered 8 comets", Answer: "Caroline Herschel"}
+	if !m.Match(person, "Herschel") {
+		t.Error("Surname should be accepted when the clue is about a person")
+	}
+	lower := &question.Question{Category: "CHEMISTRY", Question: "He might study this kind of bond", Answer: "covalent bond"}
+	if m.Match(lower, "bond") {
+		t.Error("Answers not capitalized like a name should not match by last word")
+	}
+}
+
+func TestDamerauLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"garfield", "garfeild", 1},
+		{"ca", "abc", 3},
+		{"dvořák", "dvorak", 2},
+	}
+	for _, tt := range tests {
+		if got := damerauLevenshtein([]rune(tt.a), []rune(tt.b)); got != tt.expected {
+			t.Errorf("damerauLevenshtein(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestParseStrictness(t *testing.T) {
+	for s := StrictnessExact; s <= StrictnessLenient; s++ {
+		if parsed, err := ParseStrictness(s.String()); err != nil || parsed != s {
+			t.Errorf("ParseStrictness(%q) = %v, %v", s.String(), parsed, err)
+		}
+	}
+	if s, err := ParseStrictness(" Strict "); err != nil || s != StrictnessStrict {
+		t.Errorf("ParseStrictness should ignore case and spaces, got %v, %v", s, err)
+	}
+	if _, err := ParseStrictness("fuzzy"); err == nil {
+		t.Error("Expected an error for an unknown strictness")
+	}
+}
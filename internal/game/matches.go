@@ -0,0 +1,242 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"trebek/internal/question"
+)
+
+var matchHistoryFile = "matches.json"
+
+// maxMatchQuestions caps the length of a match.
+const maxMatchQuestions = 100
+
+// A Match is a game of a fixed number of questions, scored separately from
+// the all-time scoreboard.
+type Match struct {
+	Questions int            `json:"questions"` // Questions the match was set to run
+	Asked     int            `json:"asked"`     // Questions asked so far
+	Scores    map[string]int `json:"scores"`
+	Correct   map[string]int `json:"correct"` // Correct answers per player
+	StartedBy string         `json:"started_by"`
+	StartedAt time.Time      `json:"started_at"`
+	EndedAt   time.Time      `json:"ended_at,omitzero"`
+	Completed bool           `json:"completed"` // False if the match was stopped early
+	Final     bool           `json:"final"`     // Ends with Final Jeopardy
+}
+
+// PlayerScore is one line of a ranking.
+type PlayerScore struct {
+	Name  string
+	Score int
+}
+
+// RankScores sorts scores from best to worst, breaking ties by name.
+func RankScores(scores map[string]int) []PlayerScore {
+	ranked := make([]PlayerScore, 0, len(scores))
+	for name, score := range scores {
+		ranked = append(ranked, PlayerScore{name, score})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Name < ranked[j].Name
+	})
+	return ranked
+}
+
+// Standings returns the match scores from best to worst.
+func (m *Match) Standings() []PlayerScore {
+	return RankScores(m.Scores)
+}
+
+// Answered returns how many questions were answered correctly.
+func (m *Match) Answered() int {
+	answered := 0
+	for _, n := range m.Correct {
+		answered += n
+	}
+	return answered
+}
+
+// Done reports whether every question of the match has been asked.
+func (m *Match) Done() bool {
+	return m.Asked >= m.Questions
+}
+
+func (m *Match) clone() *Match {
+	c := *m
+	c.Scores = make(map[string]int, len(m.Scores))
+	for name, score := range m.Scores {
+		c.Scores[name] = score
+	}
+	c.Correct = make(map[string]int, len(m.Correct))
+	for name, n := range m.Correct {
+		c.Correct[name] = n
+	}
+	return &c
+}
+
+// StartMatch begins a match of the given number of questions, optionally
+// followed by Final Jeopardy.
+func (g *Game) StartMatch(questions int, startedBy string, final bool) error {
+	if questions < 1 || questions > maxMatchQuestions {
+		return errors.New("a match must have between 1 and 100 questions")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.match != nil {
+		return errors.New("a match is already in progress")
+	}
+	if g.duel != nil && g.duel.Accepted {
+		return errors.New("a duel is under way")
+	}
+	g.match = &Match{
+		Questions: questions,
+		Scores:    make(map[string]int),
+		Correct:   make(map[string]int),
+		StartedBy: startedBy,
+		StartedAt: time.Now(),
+		Final:     final,
+	}
+	return nil
+}
+
+// CurrentMatch returns a copy of the match in progress, or nil.
+func (g *Game) CurrentMatch() *Match {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.match == nil {
+		return nil
+	}
+	return g.match.clone()
+}
+
+// EndMatch finishes the match in progress, saves it to the match history
+// and returns it. It returns nil if no match is in progress.
+func (g *Game) EndMatch() *Match {
+	g.mu.Lock()
+	m := g.match
+	g.match = nil
+	g.final = nil
+	g.mu.Unlock()
+	if m == nil {
+		return nil
+	}
+	m.EndedAt = time.Now()
+	m.Completed = m.Done()
+	g.saveMatch(m)
+	return m
+}
+
+// AwardCorrect credits a correct answer to the current question worth points
+// to a player, on the scoreboard and in the match in progress. In team mode
+// the player's team is credited as well.
+func (g *Game) AwardCorrect(player string, points int) {
+	g.mu.Lock()
+	q, reason := g.CurrentQuestion, ReasonCorrect
+	if g.dailyDouble != nil {
+		reason = ReasonDailyDouble
+	}
+	g.mu.Unlock()
+	g.AwardCorrectFor(player, points, reason, q)
+}
+
+// AwardCorrectFor credits a correct answer to q, which may already be
+// closed, like AwardCorrect, recording the reason, e.g. ReasonDailyDouble.
+func (g *Game) AwardCorrectFor(player string, points int, reason string, q *question.Question) {
+	questionID := questionID(q)
+	g.mu.Lock()
+	var team string
+	if g.settings.TeamMode {
+		team = g.Scoreboard.TeamOf(player)
+	}
+	if g.match != nil {
+		g.match.Scores[player] += points
+		g.match.Correct[player]++
+	}
+	g.mu.Unlock()
+
+	// Saving the scores goes to disk, so it is done outside g.mu
+	g.Scoreboard.AddScoreFor(player, points, reason, questionID)
+	if team != "" {
+		g.Scoreboard.AddTeamScoreFor(team, points, reason, questionID)
+	}
+}
+
+// Charge subtracts points from a player on the scoreboard and in the match
+// in progress, for a reason such as ReasonHint, on the current question.
+func (g *Game) Charge(player string, points int, reason string) {
+	g.ChargeFor(player, points, reason, g.GetCurrentQuestion())
+}
+
+// ChargeFor subtracts points from a player like Charge, on q, which may
+// already be closed.
+func (g *Game) ChargeFor(player string, points int, reason string, q *question.Question) {
+	g.adjustScore(player, -points, reason, questionID(q))
+}
+
+// adjustScore changes a player's score on the scoreboard and in the match in
+// progress without counting an answer.
+func (g *Game) adjustScore(player string, points int, reason, questionID string) {
+	g.Scoreboard.AddScoreFor(player, points, reason, questionID)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.match != nil {
+		g.match.Scores[player] += points
+	}
+}
+
+// questionID returns the ID of q, or "" if there is no question.
+func questionID(q *question.Question) string {
+	if q == nil {
+		return ""
+	}
+	return q.ID()
+}
+
+// MatchHistory returns the channel's finished matches, oldest first.
+func (g *Game) MatchHistory() []*Match {
+	g.historyMu.Lock()
+	defer g.historyMu.Unlock()
+	return g.loadMatches()
+}
+
+// saveMatch appends a finished match to the channel's match history.
+func (g *Game) saveMatch(m *Match) {
+	g.historyMu.Lock()
+	defer g.historyMu.Unlock()
+	history := append(g.loadMatches(), m)
+	bytes, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		log.Printf("Error marshalling match history: %v", err)
+		return
+	}
+	if err := writeFileAtomic(channelFile(matchHistoryFile, g.GameChannel), bytes); err != nil {
+		log.Printf("Error saving match history: %v", err)
+	}
+}
+
+// loadMatches reads the match history. It must be called with g.historyMu held.
+func (g *Game) loadMatches() []*Match {
+	path := channelFile(matchHistoryFile, g.GameChannel)
+	bytes, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading match history: %v", err)
+		}
+		return nil
+	}
+	var history []*Match
+	if err := json.Unmarshal(bytes, &history); err != nil {
+		log.Printf("Error unmarshalling match history: %v", err)
+		return nil
+	}
+	return history
+}
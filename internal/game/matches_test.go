@@ -0,0 +1,83 @@
+package game
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"trebek/internal/question"
+)
+
+func TestRankScores(t *testing.T) {
+	got := RankScores(map[string]int{"carol": 400, "alice": 1200, "bob": 400, "dave": -200})
+	expected := []PlayerScore{{"alice", 1200}, {"bob", 400}, {"carol", 400}, {"dave", -200}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("RankScores() = %v, expected %v", got, expected)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	dir := t.TempDir()
+	originalScoreboardFile, originalMatchHistoryFile := scoreboardFile, matchHistoryFile
+	scoreboardFile = filepath.Join(dir, "scoreboard.json")
+	matchHistoryFile = filepath.Join(dir, "matches.json")
+	defer func() { scoreboardFile, matchHistoryFile = originalScoreboardFile, originalMatchHistoryFile }()
+
+	game := NewGame(newMockQuestionSource([]*question.Question{
+		{Category: "Test", Question: "Q1", Answer: "A1"},
+		{Category: "Test", Question: "Q2", Answer: "A2"},
+		{Category: "Test", Question: "Q3", Answer: "A3"},
+	}), "#trivia")
+	game.AwardCorrect("alice", 100) // Before the match: all-time only
+
+	if err := game.StartMatch(0, "alice", false); err == nil {
+		t.Error("Expected an empty match to be refused")
+	}
+	if err := game.StartMatch(2, "alice", false); err != nil {
+		t.Fatalf("StartMatch failed: %v", err)
+	}
+	if err := game.StartMatch(2, "bob", false); err == nil {
+		t.Error("Expected a second match to be refused")
+	}
+
+	game.StartRound()
+	game.AwardCorrect("alice", 400)
+	game.Charge("bob", 40, ReasonHint)
+	game.ClearCurrentQuestion()
+	game.StartRound()
+	game.AwardCorrect("bob", 800)
+	game.ClearCurrentQuestion()
+
+	m := game.CurrentMatch()
+	if m == nil || m.Asked != 2 || !m.Done() {
+		t.Fatalf("Expected two questions asked, got %+v", m)
+	}
+	if !reflect.DeepEqual(m.Standings(), []PlayerScore{{"bob", 760}, {"alice", 400}}) {
+		t.Errorf("Unexpected standings: %v", m.Standings())
+	}
+	if m.Answered() != 2 {
+		t.Errorf("Expected 2 answered questions, got %d", m.Answered())
+	}
+	if score := game.Scoreboard.GetScore("alice"); score != 500 {
+		t.Errorf("Expected the all-time score to include every answer, got %d", score)
+	}
+
+	ended := game.EndMatch()
+	if ended == nil || !ended.Completed || ended.EndedAt.IsZero() {
+		t.Fatalf("Unexpected finished match: %+v", ended)
+	}
+	if game.CurrentMatch() != nil || game.EndMatch() != nil {
+		t.Error("Expected no match after ending it")
+	}
+
+	game.StartMatch(5, "carol", false)
+	game.StartRound()
+	game.EndMatch()
+	history := game.MatchHistory()
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 matches in the history, got %d", len(history))
+	}
+	if history[0].Scores["bob"] != 760 || history[0].StartedBy != "alice" || history[1].Completed {
+		t.Errorf("Unexpected match history: %+v, %+v", history[0], history[1])
+	}
+}
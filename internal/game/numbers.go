@@ -0,0 +1,280 @@
+package game
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var unitWords = map[string]int{
+	"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+	"thirteen": 13, "fourteen": 14, "fifteen": 15, "sixteen": 16,
+	"seventeen": 17, "eighteen": 18, "nineteen": 19,
+}
+
+var tensWords = map[string]int{
+	"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50,
+	"sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+}
+
+var scaleWords = map[string]int{
+	"hundred": 100, "thousand": 1000, "million": 1000000, "billion": 1000000000,
+}
+
+var ordinalWords = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "sixth": 6,
+	"seventh": 7, "eighth": 8, "ninth": 9, "tenth": 10, "eleventh": 11,
+	"twelfth": 12, "thirteenth": 13, "fourteenth": 14, "fifteenth": 15,
+	"sixteenth": 16, "seventeenth": 17, "eighteenth": 18, "nineteenth": 19,
+	"twentieth": 20, "thirtieth": 30, "fortieth": 40, "fiftieth": 50,
+	"sixtieth": 60, "seventieth": 70, "eightieth": 80, "ninetieth": 90,
+	"hundredth": 100, "thousandth": 1000, "millionth": 1000000,
+}
+
+// denominatorWords name the bottom of a fraction when they follow a number,
+// as in "one half" or "three quarters". Ordinals ("two thirds") are handled
+// separately; "second" is left out because "one second" is a time.
+var denominatorWords = map[string]int{
+	"half": 2, "halves": 2, "quarter": 4, "quarters": 4,
+}
+
+var (
+	groupedDigits  = regexp.MustCompile(`^\d{1,3}(,\d{3})+$`)
+	ordinalDigits  = regexp.MustCompile(`^(\d+)(st|nd|rd|th)$`)
+	digitFraction  = regexp.MustCompile(`^(\d+)/(\d+)$`)
+	romanNumeral   = regexp.MustCompile(`^M{0,3}(CM|CD|D?C{0,3})(XC|XL|L?X{0,3})(IX|IV|V?I{0,3})$`)
+	romanValues    = map[byte]int{'I': 1, 'V': 5, 'X': 10, 'L': 50, 'C': 100, 'D': 500, 'M': 1000}
+	numberReplacer = strings.NewReplacer("-", " ", "%", " percent ", "per cent", "percent")
+)
+
+// numberForms returns s with its numbers in canonical form and, if s contains
+// Roman numerals, a second form with those converted as well. Roman numerals
+// are only recognized in upper case unless anyCase is set, so answers such as
+// "Mix" are not mistaken for numbers.
+func numberForms(s string, anyCase bool) []string {
+	forms := []string{canonicalNumbers(s)}
+	if roman, ok := convertRoman(s, anyCase); ok {
+		if form := canonicalNumbers(roman); form != forms[0] {
+			forms = append(forms, form)
+		}
+	}
+	return forms
+}
+
+// canonicalNumbers rewrites the numbers in s as digits so that "seven",
+// "7", "1,000" and "one thousand", "4th" and "fourth", "1/2" and "one half",
+// or "50%" and "fifty percent" all read the same. Fractions become "N over D"
+// so that "1/2" does not collapse into "12" when punctuation is removed.
+func canonicalNumbers(s string) string {
+	tokens := strings.Fields(numberReplacer.Replace(s))
+	var out []string
+	for i := 0; i < len(tokens); {
+		word := numberWord(tokens[i])
+
+		// "the" before an ordinal is dropped: "Henry the Eighth" is "Henry 8".
+		if word == "the" && i+1 < len(tokens) {
+			if _, ok := ordinalValue(numberWord(tokens[i+1])); ok {
+				i++
+				continue
+			}
+		}
+
+		// "a half", "a third", "a hundred"
+		if (word == "a" || word == "an") && i+1 < len(tokens) {
+			next := numberWord(tokens[i+1])
+			if d, ok := denominator(next); ok {
+				out = append(out, "1 over "+strconv.Itoa(d))
+				i += 2
+				continue
+			}
+			if scale, ok := scaleWords[next]; ok {
+				out = append(out, strconv.Itoa(scale))
+				i += 2
+				continue
+			}
+		}
+
+		if m := digitFraction.FindStringSubmatch(word); m != nil {
+			out = append(out, m[1]+" over "+m[2])
+			i++
+			continue
+		}
+
+		if value, n := parseCardinal(tokens[i:]); n > 0 {
+			i += n
+			if i < len(tokens) {
+				if d, ok := denominator(numberWord(tokens[i])); ok {
+					out = append(out, strconv.Itoa(value)+" over "+strconv.Itoa(d))
+					i++
+					continue
+				}
+			}
+			out = append(out, strconv.Itoa(value))
+			continue
+		}
+
+		if value, ok := ordinalValue(word); ok {
+			out = append(out, strconv.Itoa(value))
+			i++
+			continue
+		}
+		if d, ok := denominatorWords[word]; ok && !strings.HasSuffix(word, "s") {
+			out = append(out, "1 over "+strconv.Itoa(d)) // "half", "quarter"
+			i++
+			continue
+		}
+
+		out = append(out, tokens[i])
+		i++
+	}
+	return strings.Join(out, " ")
+}
+
+// numberWord lowercases a token and strips the punctuation around it,
+// keeping the commas and slashes that can appear inside numbers.
+func numberWord(token string) string {
+	return strings.TrimFunc(strings.ToLower(token), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// parseCardinal reads a cardinal number from the start of tokens, written
+// in digits ("1,000", "3 million") or words ("one thousand and five"). It
+// returns the value and how many tokens it used, or 0 tokens if there is no
+// number. A trailing ordinal word completes it: "twenty first" is 21.
+func parseCardinal(tokens []string) (int, int) {
+	total, current, used := 0, 0, 0
+	sawNumber := false
+	for used < len(tokens) {
+		word := numberWord(tokens[used])
+		if groupedDigits.MatchString(word) {
+			word = strings.ReplaceAll(word, ",", "")
+		}
+		switch {
+		case isDigits(word) && !sawNumber:
+			n, err := strconv.Atoi(word)
+			if err != nil {
+				return 0, 0
+			}
+			current = n
+		case unitWords[word] != 0 || word == "zero":
+			if sawNumber && current%10 != 0 {
+				return total + current, used // "one two" is two numbers
+			}
+			current += unitWords[word]
+		case tensWords[word] != 0:
+			if sawNumber && current%100 != 0 {
+				return total + current, used
+			}
+			current += tensWords[word]
+		case scaleWords[word] != 0:
+			if !sawNumber {
+				current = 1 // "hundred" on its own, as left by "a hundred" without the article
+			}
+			if word == "hundred" {
+				current *= 100
+			} else {
+				total += current * scaleWords[word]
+				current = 0
+			}
+		case word == "and" && sawNumber && used+1 < len(tokens) && isNumberWord(numberWord(tokens[used+1])):
+			// "one hundred and five"
+		case sawNumber && current%10 == 0 && ordinalWords[word] != 0 && ordinalWords[word] < 10:
+			return total + current + ordinalWords[word], used + 1 // "twenty first"
+		default:
+			if !sawNumber {
+				return 0, 0
+			}
+			return total + current, used
+		}
+		sawNumber = true
+		used++
+		if isDigits(word) && (used == len(tokens) || scaleWords[numberWord(tokens[used])] == 0) {
+			break // Digits only combine with a following scale: "3 million"
+		}
+	}
+	return total + current, used
+}
+
+func isNumberWord(word string) bool {
+	_, unit := unitWords[word]
+	_, tens := tensWords[word]
+	return unit || tens
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ordinalValue parses "fourth", "4th" or "twentieth".
+func ordinalValue(word string) (int, bool) {
+	if n, ok := ordinalWords[word]; ok {
+		return n, true
+	}
+	if m := ordinalDigits.FindStringSubmatch(word); m != nil {
+		n, err := strconv.Atoi(m[1])
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// denominator parses the bottom of a spoken fraction: "half", "quarters",
+// "third" or "fifths".
+func denominator(word string) (int, bool) {
+	if d, ok := denominatorWords[word]; ok {
+		return d, true
+	}
+	singular := strings.TrimSuffix(word, "s")
+	if singular == "second" {
+		return 0, false
+	}
+	if d, ok := ordinalWords[singular]; ok && d > 2 {
+		return d, true
+	}
+	return 0, false
+}
+
+// convertRoman replaces Roman numeral tokens with digits. A lone I, V or X
+// only counts after another word, as in "Henry V" or "Malcolm X".
+func convertRoman(s string, anyCase bool) (string, bool) {
+	tokens := strings.Fields(s)
+	changed := false
+	for i, token := range tokens {
+		word := strings.TrimFunc(token, func(r rune) bool { return !unicode.IsLetter(r) })
+		if anyCase {
+			word = strings.ToUpper(word)
+		}
+		if word == "" || !romanNumeral.MatchString(word) {
+			continue
+		}
+		if len(word) == 1 && (i == 0 || !strings.ContainsAny(word, "IVX")) {
+			continue
+		}
+		tokens[i] = strconv.Itoa(romanValue(word))
+		changed = true
+	}
+	return strings.Join(tokens, " "), changed
+}
+
+func romanValue(numeral string) int {
+	total := 0
+	for i := 0; i < len(numeral); i++ {
+		v := romanValues[numeral[i]]
+		if i+1 < len(numeral) && v < romanValues[numeral[i+1]] {
+			total -= v
+		} else {
+			total += v
+		}
+	}
+	return total
+}
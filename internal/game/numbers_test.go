@@ -0,0 +1,67 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"seven", "7"},
+		{"Seven Samurai", "7 Samurai"},
+		{"twenty-one", "21"},
+		{"one hundred and five", "105"},
+		{"two thousand twenty-four", "2024"},
+		{"1,000", "1000"},
+		{"1,000,000", "1000000"},
+		{"3 million", "3000000"},
+		{"a thousand", "1000"},
+		{"hundred", "100"},
+		{"4th", "4"},
+		{"fourth", "4"},
+		{"twenty-first", "21"},
+		{"Henry the Eighth", "Henry 8"},
+		{"1/2", "1 over 2"},
+		{"one half", "1 over 2"},
+		{"a half", "1 over 2"},
+		{"three quarters", "3 over 4"},
+		{"two thirds", "2 over 3"},
+		{"one second", "1 2"},
+		{"50%", "50 percent"},
+		{"fifty per cent", "50 percent"},
+		{"one two three", "1 2 3"},
+		{"1.5", "1.5"},
+		{"Catch-22", "Catch 22"},
+		{"the Nile", "the Nile"},
+	}
+	for _, tt := range tests {
+		if got := canonicalNumbers(tt.input); got != tt.expected {
+			t.Errorf("canonicalNumbers(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestNumberForms(t *testing.T) {
+	tests := []struct {
+		input    string
+		anyCase  bool
+		expected []string
+	}{
+		{"Louis XIV", false, []string{"Louis XIV", "Louis 14"}},
+		{"Louis xiv", false, []string{"Louis xiv"}},
+		{"louis xiv", true, []string{"louis xiv", "louis 14"}},
+		{"Super Bowl XLII", false, []string{"Super Bowl XLII", "Super Bowl 42"}},
+		{"Malcolm X", false, []string{"Malcolm X", "Malcolm 10"}},
+		{"I, Robot", false, []string{"I, Robot"}},
+		{"MIXED", false, []string{"MIXED"}},
+		{"Vitamin C", false, []string{"Vitamin C"}},
+	}
+	for _, tt := range tests {
+		if got := numberForms(tt.input, tt.anyCase); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("numberForms(%q, %v) = %q, expected %q", tt.input, tt.anyCase, got, tt.expected)
+		}
+	}
+}
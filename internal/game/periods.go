@@ -0,0 +1,81 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PeriodKinds are the lengths of time leaderboards are kept for, shortest first.
+var PeriodKinds = []string{"day", "week", "month", "year"}
+
+// PeriodLabel names the period of the given kind that t falls in, e.g.
+// "2026-09-15", "2026-W38", "2026-09" or "2026". Weeks are ISO weeks.
+func PeriodLabel(kind string, t time.Time) string {
+	switch kind {
+	case "day":
+		return t.Format("2006-01-02")
+	case "week":
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case "month":
+		return t.Format("2006-01")
+	case "year":
+		return t.Format("2006")
+	}
+	return ""
+}
+
+// ParsePeriod reads a period as a player asks for it: "today", "week",
+// "month" or "year" for the one under way at now, or the label of any
+// period, such as "2026-09". It returns the period's kind and label.
+func ParsePeriod(s string, now time.Time) (kind, label string, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "today", "day":
+		return "day", PeriodLabel("day", now), nil
+	case "week", "month", "year":
+		return s, PeriodLabel(s, now), nil
+	}
+	var year, week int
+	if n, _ := fmt.Sscanf(s, "%4d-w%2d", &year, &week); n == 2 && week >= 1 && week <= 53 {
+		label = fmt.Sprintf("%d-W%02d", year, week)
+		if label == strings.ToUpper(s) {
+			return "week", label, nil
+		}
+	}
+	for _, layout := range []struct{ kind, format string }{
+		{"day", "2006-01-02"},
+		{"month", "2006-01"},
+		{"year", "2006"},
+	} {
+		if _, err := time.Parse(layout.format, s); err == nil {
+			return layout.kind, s, nil
+		}
+	}
+	return "", "", fmt.Errorf("%q is not a period like week, month, 2026-09 or 2026-W38", s)
+}
+
+// addToPeriods counts an event towards the leaderboards of the periods it
+// falls in. It must be called with sb.mu held.
+func (sb *Scoreboard) addToPeriods(event ScoreEvent) {
+	for _, kind := range PeriodKinds {
+		label := PeriodLabel(kind, event.Time)
+		if sb.periods[label] == nil {
+			sb.periods[label] = make(map[string]int)
+		}
+		sb.periods[label][event.Player] += event.Delta
+	}
+}
+
+// PeriodScores returns the scores players made in a period, by player. The
+// period is named by its label, as returned by PeriodLabel.
+func (sb *Scoreboard) PeriodScores(label string) map[string]int {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	scores := make(map[string]int, len(sb.periods[label]))
+	for player, score := range sb.periods[label] {
+		scores[player] = score
+	}
+	return scores
+}
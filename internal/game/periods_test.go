@@ -0,0 +1,68 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPeriodLabel(t *testing.T) {
+	at := time.Date(2027, time.January, 1, 12, 0, 0, 0, time.UTC) // A Friday in ISO week 53 of 2026
+	expected := map[string]string{"day": "2027-01-01", "week": "2026-W53", "month": "2027-01", "year": "2027"}
+	for kind, label := range expected {
+		if got := PeriodLabel(kind, at); got != label {
+			t.Errorf("PeriodLabel(%s) = %q, expected %q", kind, got, label)
+		}
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	now := time.Date(2026, time.September, 15, 9, 0, 0, 0, time.UTC)
+	valid := []struct{ input, kind, label string }{
+		{"today", "day", "2026-09-15"},
+		{"Week", "week", "2026-W38"},
+		{"month", "month", "2026-09"},
+		{"year", "year", "2026"},
+		{"2026-08", "month", "2026-08"},
+		{"2026-w07", "week", "2026-W07"},
+		{"2025-12-31", "day", "2025-12-31"},
+		{"2024", "year", "2024"},
+	}
+	for _, tt := range valid {
+		kind, label, err := ParsePeriod(tt.input, now)
+		if err != nil || kind != tt.kind || label != tt.label {
+			t.Errorf("ParsePeriod(%q) = %q, %q, %v; expected %q, %q", tt.input, kind, label, err, tt.kind, tt.label)
+		}
+	}
+	for _, input := range []string{"fortnight", "2026-13", "2026-W54", "2026-W7", "2026-9", "26"} {
+		if _, _, err := ParsePeriod(input, now); err == nil {
+			t.Errorf("Expected ParsePeriod(%q) to fail", input)
+		}
+	}
+}
+
+func TestPeriodScores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scoreboard.json")
+	sb := newScoreboardAt(path)
+	sb.AddScore("alice", 400)
+	sb.AddScore("bob", 800)
+	sb.AddScore("alice", -100)
+
+	month := PeriodLabel("month", time.Now())
+	if scores := sb.PeriodScores(month); scores["alice"] != 300 || scores["bob"] != 800 {
+		t.Errorf("Unexpected scores for %s: %v", month, scores)
+	}
+	if scores := sb.PeriodScores("1999-01"); len(scores) != 0 {
+		t.Errorf("Expected no scores for 1999-01, got %v", scores)
+	}
+
+	// The leaderboards are rebuilt from the event log and survive a reset
+	sb.Reset()
+	reloaded := newScoreboardAt(path)
+	if scores := reloaded.PeriodScores(PeriodLabel("week", time.Now())); scores["alice"] != 300 || scores["bob"] != 800 {
+		t.Errorf("Period scores not reloaded: %v", scores)
+	}
+	if len(reloaded.Scores()) != 0 {
+		t.Errorf("Expected the all-time scores to stay reset, got %v", reloaded.Scores())
+	}
+}
@@ -0,0 +1,214 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+var ratingsFile = "ratings.json"
+
+const (
+	InitialRating    = 1500 // Rating of a player who has not been rated yet
+	ratingK          = 32   // Most rating a player can win or lose on one question
+	maxRatingHistory = 1000 // Rating events kept in the history
+)
+
+// PlayerRating is a player's Elo rating.
+type PlayerRating struct {
+	Rating float64 `json:"rating"`
+	Peak   float64 `json:"peak"`
+	Events int     `json:"events"` // Questions the player was rated on
+}
+
+// RatingEvent is a question that changed ratings: its winner against
+// everyone else who attempted it.
+type RatingEvent struct {
+	Time    time.Time          `json:"time"`
+	Winner  string             `json:"winner"`
+	Changes map[string]float64 `json:"changes"` // Rating change, by player
+}
+
+// Ratings rates players on their skill rather than how long they have
+// played. Every question that someone answers correctly is a multi-player
+// event in which the winner beats everyone else who attempted it, with the
+// winner gaining what the others lose.
+type Ratings struct {
+	Players map[string]*PlayerRating `json:"players"`
+	History []RatingEvent            `json:"history"` // Most recent last
+	mu      sync.Mutex
+	path    string // File the ratings are persisted to
+}
+
+// NewChannelRatings creates a channel's ratings, loading them from file if
+// they were saved before.
+func NewChannelRatings(channel string) *Ratings {
+	r := &Ratings{
+		Players: make(map[string]*PlayerRating),
+		path:    channelFile(ratingsFile, channel),
+	}
+	r.load()
+	return r
+}
+
+// expectedScore is the chance, by Elo, that a player rated a beats one rated b.
+func expectedScore(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// Get returns a player's rating. Players who have not been rated yet have the
+// initial rating.
+func (r *Ratings) Get(player string) PlayerRating {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p, ok := r.Players[player]; ok {
+		return *p
+	}
+	return PlayerRating{Rating: InitialRating, Peak: InitialRating}
+}
+
+// Record rates a question won by winner against losers. The winner's gain is
+// split over the losers, so a question is worth the same however many
+// players attempted it. A question nobody else attempted changes nothing.
+func (r *Ratings) Record(winner string, losers []string) *RatingEvent {
+	if len(losers) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	player := func(name string) *PlayerRating {
+		p, ok := r.Players[name]
+		if !ok {
+			p = &PlayerRating{Rating: InitialRating, Peak: InitialRating}
+			r.Players[name] = p
+		}
+		return p
+	}
+	event := RatingEvent{Time: time.Now(), Winner: winner, Changes: make(map[string]float64)}
+	w := player(winner)
+	for _, name := range losers {
+		l := player(name)
+		change := ratingK * (1 - expectedScore(w.Rating, l.Rating)) / float64(len(losers))
+		event.Changes[winner] += change
+		event.Changes[name] -= change
+	}
+	for name, change := range event.Changes {
+		p := r.Players[name]
+		p.Rating += change
+		p.Peak = math.Max(p.Peak, p.Rating)
+		p.Events++
+	}
+	r.History = append(r.History, event)
+	if len(r.History) > maxRatingHistory {
+		r.History = r.History[len(r.History)-maxRatingHistory:]
+	}
+	r.save()
+	return &event
+}
+
+// Ranking returns every rated player's rating, rounded, by player.
+func (r *Ratings) Ranking() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ratings := make(map[string]int, len(r.Players))
+	for name, p := range r.Players {
+		ratings[name] = int(math.Round(p.Rating))
+	}
+	return ratings
+}
+
+// PlayerHistory returns the events player was rated in, most recent last.
+func (r *Ratings) PlayerHistory(player string) []RatingEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []RatingEvent
+	for _, event := range r.History {
+		if _, ok := event.Changes[player]; ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// save saves the ratings to a file. It must be called with r.mu held.
+func (r *Ratings) save() {
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		log.Printf("Error marshalling ratings: %v", err)
+		return
+	}
+	if err := writeFileAtomic(r.path, bytes); err != nil {
+		log.Printf("Error saving ratings: %v", err)
+	}
+}
+
+// load loads the ratings from a file.
+func (r *Ratings) load() {
+	bytes, err := os.ReadFile(r.path) // #nosec G304
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading ratings file: %v", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(bytes, r); err != nil {
+		log.Printf("Error unmarshalling ratings: %v", err)
+	}
+	if r.Players == nil {
+		r.Players = make(map[string]*PlayerRating)
+	}
+}
+
+// Attempt checks a player's answer to the current question, like
+// CheckAnswer, and counts the player in the question's rating event. The
+// first player to answer correctly wins it.
+func (g *Game) Attempt(player, answer string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.CurrentQuestion == nil {
+		return false
+	}
+	return g.attempt(player, answer)
+}
+
+// attempt does the work of Attempt for the current question, which must be
+// set. It must be called with g.mu held.
+func (g *Game) attempt(player, answer string) bool {
+	if g.attempts == nil {
+		g.attempts = make(map[string]bool)
+	}
+	g.attempts[player] = true
+	correct := g.Matcher.Match(g.CurrentQuestion, answer)
+	if correct && g.questionWinner == "" {
+		g.questionWinner = player
+	}
+	return correct
+}
+
+// questionResult returns the winner of the question being closed, if someone
+// won it, and everyone else who attempted it, and forgets its attempts. It
+// must be called with g.mu held.
+func (g *Game) questionResult() (winner string, losers []string) {
+	winner = g.questionWinner
+	if winner != "" {
+		for player := range g.attempts {
+			if player != winner {
+				losers = append(losers, player)
+			}
+		}
+	}
+	g.attempts = nil
+	g.questionWinner = ""
+	return winner, losers
+}
+
+// rateQuestion rates a closed question's result, if someone won it. It must
+// be called without g.mu held, as the ratings are saved.
+func (g *Game) rateQuestion(winner string, losers []string) {
+	if winner != "" {
+		g.Ratings.Record(winner, losers)
+	}
+}
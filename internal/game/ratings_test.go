@@ -0,0 +1,83 @@
+package game
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"trebek/internal/question"
+)
+
+func TestRatingsRecord(t *testing.T) {
+	originalRatingsFile := ratingsFile
+	ratingsFile = filepath.Join(t.TempDir(), "ratings.json")
+	defer func() { ratingsFile = originalRatingsFile }()
+
+	r := NewChannelRatings("#trivia")
+	if r.Record("alice", nil) != nil {
+		t.Error("Expected a question nobody else attempted not to be rated")
+	}
+	event := r.Record("alice", []string{"bob"})
+	if event == nil || math.Abs(event.Changes["alice"]-16) > 1e-9 || math.Abs(event.Changes["bob"]+16) > 1e-9 {
+		t.Fatalf("Expected evenly rated players to trade 16 points, got %+v", event)
+	}
+
+	// Beating a weaker player is worth less than beating a stronger one
+	weak := r.Record("alice", []string{"bob"}).Changes["alice"]
+	strong := r.Record("bob", []string{"alice"}).Changes["bob"]
+	if weak >= 16 || strong <= 16 {
+		t.Errorf("Expected the favourite to gain less (%.2f) than the underdog (%.2f)", weak, strong)
+	}
+
+	// The gain is split over everyone who missed
+	event = r.Record("carol", []string{"dave", "erin"})
+	if math.Abs(event.Changes["carol"]-16) > 1e-9 || math.Abs(event.Changes["dave"]+8) > 1e-9 {
+		t.Errorf("Unexpected changes: %+v", event.Changes)
+	}
+
+	reloaded := NewChannelRatings("#trivia")
+	if got, want := reloaded.Get("alice"), r.Get("alice"); got != want || got.Events != 3 {
+		t.Errorf("Ratings not reloaded: got %+v, expected %+v", got, want)
+	}
+	if history := reloaded.PlayerHistory("bob"); len(history) != 3 || history[2].Winner != "bob" {
+		t.Errorf("Unexpected history for bob: %+v", history)
+	}
+	if rating := reloaded.Get("frank"); rating.Rating != InitialRating || rating.Events != 0 {
+		t.Errorf("Expected an unrated player to have the initial rating, got %+v", rating)
+	}
+}
+
+func TestQuestionRating(t *testing.T) {
+	dir := t.TempDir()
+	originalScoreboardFile, originalRatingsFile := scoreboardFile, ratingsFile
+	scoreboardFile = filepath.Join(dir, "scoreboard.json")
+	ratingsFile = filepath.Join(dir, "ratings.json")
+	defer func() { scoreboardFile, ratingsFile = originalScoreboardFile, originalRatingsFile }()
+
+	game := NewGame(newMockQuestionSource([]*question.Question{
+		{Category: "RIVERS", Question: "It flows through Cairo", Answer: "the Nile"},
+		{Category: "RIVERS", Question: "It flows through Baghdad", Answer: "the Tigris"},
+	}), "#trivia")
+
+	if game.Attempt("alice", "Nile") {
+		t.Error("Expected no attempts without a question")
+	}
+	game.StartRound()
+	game.Attempt("bob", "Danube")
+	game.Attempt("alice", game.GetCurrentQuestion().Answer)
+	game.Attempt("bob", game.GetCurrentQuestion().Answer) // Too late to win
+	game.ClearCurrentQuestion()
+	if game.Ratings.Get("alice").Rating <= InitialRating || game.Ratings.Get("bob").Rating >= InitialRating {
+		t.Errorf("Expected alice to gain rating from bob: %v", game.Ratings.Ranking())
+	}
+
+	// Questions nobody wins change nothing
+	before := game.Ratings.Ranking()
+	game.StartRound()
+	game.Attempt("alice", "Danube")
+	game.Attempt("bob", "Rhine")
+	game.ClearCurrentQuestion()
+	if after := game.Ratings.Ranking(); after["alice"] != before["alice"] || after["bob"] != before["bob"] {
+		t.Errorf("Expected an unanswered question not to be rated: %v", after)
+	}
+}
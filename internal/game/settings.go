@@ -0,0 +1,380 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var settingsFile = "settings.json"
+
+// Defaults for the game settings.
+const (
+	DefaultQuestionTimeout   = 30 * time.Second // Time to answer a question
+	DefaultQuestionInterval  = 45 * time.Second // Time from one question to the next in continuous play
+	DefaultAnswerDelay       = 5 * time.Second  // Pause after an answer before the next question
+	DefaultMaxHints          = 3                // Maximum hints per question
+	DefaultSkipVotes         = 3                // Votes needed to skip a question
+	DefaultClueValue         = 1000             // Dollars awarded for clues without a value, such as Final Jeopardy
+	DefaultHintPenalty       = 10               // Percent of the clue's value subtracted per hint
+	DefaultStandingsEvery    = 5                // Questions between match standings
+	DefaultFinalTime         = 30 * time.Second // Time to wager, and then to respond, in Final Jeopardy
+	DefaultDailyDoubleChance = 5                // Percent of clues in continuous play that are Daily Doubles
+	DefaultDuelWindow        = 20 * time.Second // Time duelists get to buzz in with an answer
+	DefaultAnnounceWinners   = "week month"     // Periods whose winners are announced when they end
+)
+
+// Settings are the tunable rules of a channel's game.
+type Settings struct {
+	QuestionTimeout   time.Duration
+	QuestionInterval  time.Duration
+	AnswerDelay       time.Duration
+	MaxHints          int
+	SkipVotes         int
+	DefaultClueValue  int // Dollars
+	HintPenalty       int // Percent of the clue's value
+	AnswerStrictness  Strictness
+	StandingsEvery    int  // Questions between match standings; 0 turns them off
+	FinalJeopardy     bool // End every match with Final Jeopardy
+	FinalTime         time.Duration
+	DailyDoubleChance int           // Percent of clues in continuous play that are Daily Doubles
+	TeamMode          bool          // Correct answers also count for the player's team
+	TeamWindow        time.Duration // Time other teams get to answer after the first correct answer; 0 closes the question at once
+	DuelWindow        time.Duration // Time duelists get to buzz in with an answer
+	AnnounceWinners   string        // Space-separated periods whose winners are announced when they end
+}
+
+// DefaultSettings returns the settings a game starts with.
+func DefaultSettings() Settings {
+	return Settings{
+		QuestionTimeout:   DefaultQuestionTimeout,
+		QuestionInterval:  DefaultQuestionInterval,
+		AnswerDelay:       DefaultAnswerDelay,
+		MaxHints:          DefaultMaxHints,
+		SkipVotes:         DefaultSkipVotes,
+		DefaultClueValue:  DefaultClueValue,
+		HintPenalty:       DefaultHintPenalty,
+		AnswerStrictness:  StrictnessNormal,
+		StandingsEvery:    DefaultStandingsEvery,
+		FinalTime:         DefaultFinalTime,
+		DailyDoubleChance: DefaultDailyDoubleChance,
+		DuelWindow:        DefaultDuelWindow,
+		AnnounceWinners:   DefaultAnnounceWinners,
+	}
+}
+
+// setting describes one key that can be read and changed by name, from the
+// config file or with !set.
+type setting struct {
+	help string
+	get  func(s *Settings) string
+	set  func(s *Settings, value string) error
+}
+
+var settingKeys = map[string]setting{
+	"question_timeout": {
+		help: "time to answer a question, e.g. 30s",
+		get:  func(s *Settings) string { return s.QuestionTimeout.String() },
+		set: func(s *Settings, v string) error {
+			return setDuration(&s.QuestionTimeout, v, 5*time.Second, 10*time.Minute)
+		},
+	},
+	"question_interval": {
+		help: "time from one question to the next, e.g. 45s",
+		get:  func(s *Settings) string { return s.QuestionInterval.String() },
+		set: func(s *Settings, v string) error {
+			return setDuration(&s.QuestionInterval, v, 5*time.Second, time.Hour)
+		},
+	},
+	"answer_delay": {
+		help: "pause after an answer before the next question, e.g. 5s",
+		get:  func(s *Settings) string { return s.AnswerDelay.String() },
+		set: func(s *Settings, v string) error {
+			return setDuration(&s.AnswerDelay, v, time.Second, 10*time.Minute)
+		},
+	},
+	"max_hints": {
+		help: "hints allowed per question",
+		get:  func(s *Settings) string { return strconv.Itoa(s.MaxHints) },
+		set:  func(s *Settings, v string) error { return setInt(&s.MaxHints, v, 0, 10) },
+	},
+	"skip_votes": {
+		help: "votes needed to skip a question",
+		get:  func(s *Settings) string { return strconv.Itoa(s.SkipVotes) },
+		set:  func(s *Settings, v string) error { return setInt(&s.SkipVotes, v, 1, 100) },
+	},
+	"default_clue_value": {
+		help: "dollars for clues without a value",
+		get:  func(s *Settings) string { return strconv.Itoa(s.DefaultClueValue) },
+		set:  func(s *Settings, v string) error { return setInt(&s.DefaultClueValue, v, 1, 100000) },
+	},
+	"hint_penalty": {
+		help: "percent of the clue's value each hint costs",
+		get:  func(s *Settings) string { return strconv.Itoa(s.HintPenalty) },
+		set:  func(s *Settings, v string) error { return setInt(&s.HintPenalty, v, 0, 100) },
+	},
+	"standings_every": {
+		help: "questions between match standings, 0 for none",
+		get:  func(s *Settings) string { return strconv.Itoa(s.StandingsEvery) },
+		set:  func(s *Settings, v string) error { return setInt(&s.StandingsEvery, v, 0, maxMatchQuestions) },
+	},
+	"final_jeopardy": {
+		help: "end every match with Final Jeopardy, on or off",
+		get:  func(s *Settings) string { return formatBool(s.FinalJeopardy) },
+		set:  func(s *Settings, v string) error { return setBool(&s.FinalJeopardy, v) },
+	},
+	"final_time": {
+		help: "time to wager, and then to respond, in Final Jeopardy",
+		get:  func(s *Settings) string { return s.FinalTime.String() },
+		set: func(s *Settings, v string) error {
+			return setDuration(&s.FinalTime, v, 10*time.Second, 5*time.Minute)
+		},
+	},
+	"daily_double_chance": {
+		help: "percent of clues in continuous play that are Daily Doubles",
+		get:  func(s *Settings) string { return strconv.Itoa(s.DailyDoubleChance) },
+		set:  func(s *Settings, v string) error { return setInt(&s.DailyDoubleChance, v, 0, 100) },
+	},
+	"team_mode": {
+		help: "count correct answers for the player's team too, on or off",
+		get:  func(s *Settings) string { return formatBool(s.TeamMode) },
+		set:  func(s *Settings, v string) error { return setBool(&s.TeamMode, v) },
+	},
+	"team_window": {
+		help: "in team mode, time other teams get to answer after the first correct answer; only each team's first counts. 0 for none",
+		get:  func(s *Settings) string { return s.TeamWindow.String() },
+		set: func(s *Settings, v string) error {
+			return setDuration(&s.TeamWindow, v, 0, 2*time.Minute)
+		},
+	},
+	"duel_window": {
+		help: "time duelists get to buzz in with an answer, e.g. 20s",
+		get:  func(s *Settings) string { return s.DuelWindow.String() },
+		set: func(s *Settings, v string) error {
+			return setDuration(&s.DuelWindow, v, 5*time.Second, 5*time.Minute)
+		},
+	},
+	"announce_winners": {
+		help: "periods whose winners are announced when they end: day, week, month and year, or off",
+		get: func(s *Settings) string {
+			if s.AnnounceWinners == "" {
+				return "off"
+			}
+			return s.AnnounceWinners
+		},
+		set: func(s *Settings, v string) error { return setPeriods(&s.AnnounceWinners, v) },
+	},
+	"answer_strictness": {
+		help: "exact, strict, normal or lenient",
+		get:  func(s *Settings) string { return s.AnswerStrictness.String() },
+		set: func(s *Settings, v string) error {
+			strictness, err := ParseStrictness(v)
+			if err != nil {
+				return err
+			}
+			s.AnswerStrictness = strictness
+			return nil
+		},
+	},
+}
+
+// SettingKeys returns the names of all settings in alphabetical order.
+func SettingKeys() []string {
+	keys := make([]string, 0, len(settingKeys))
+	for key := range settingKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SettingHelp describes a setting, or returns "" for an unknown key.
+func SettingHelp(key string) string {
+	return settingKeys[strings.ToLower(key)].help
+}
+
+// Get returns the value of a setting by name.
+func (s *Settings) Get(key string) (string, error) {
+	def, ok := settingKeys[strings.ToLower(key)]
+	if !ok {
+		return "", fmt.Errorf("unknown setting %q", key)
+	}
+	return def.get(s), nil
+}
+
+// Set validates and changes a setting by name. The settings are left
+// unchanged if the value is invalid.
+func (s *Settings) Set(key, value string) error {
+	def, ok := settingKeys[strings.ToLower(key)]
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	updated := *s
+	if err := def.set(&updated, strings.TrimSpace(value)); err != nil {
+		return fmt.Errorf("invalid %s: %w", strings.ToLower(key), err)
+	}
+	*s = updated
+	return nil
+}
+
+// setDuration parses a duration such as "30s" or "2m", or a bare number of seconds.
+func setDuration(d *time.Duration, value string, minimum, maximum time.Duration) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return fmt.Errorf("%q is not a duration like 30s", value)
+		}
+		parsed = time.Duration(seconds) * time.Second
+	}
+	if parsed < minimum || parsed > maximum {
+		return fmt.Errorf("%s is not between %s and %s", parsed, minimum, maximum)
+	}
+	*d = parsed
+	return nil
+}
+
+// setPeriods parses a list of period kinds, separated by spaces or commas,
+// or "off", and stores it shortest period first.
+func setPeriods(periods *string, value string) error {
+	if strings.EqualFold(value, "off") || value == "" {
+		*periods = ""
+		return nil
+	}
+	chosen := make(map[string]bool)
+	for _, kind := range strings.Fields(strings.ReplaceAll(strings.ToLower(value), ",", " ")) {
+		if !slices.Contains(PeriodKinds, kind) {
+			return fmt.Errorf("%q is not one of %s", kind, strings.Join(PeriodKinds, ", "))
+		}
+		chosen[kind] = true
+	}
+	var kinds []string
+	for _, kind := range PeriodKinds {
+		if chosen[kind] {
+			kinds = append(kinds, kind)
+		}
+	}
+	*periods = strings.Join(kinds, " ")
+	return nil
+}
+
+// WinnerPeriods returns the kinds of period whose winners are announced.
+func (s Settings) WinnerPeriods() []string {
+	if s.AnnounceWinners == "" {
+		return nil
+	}
+	return strings.Fields(s.AnnounceWinners)
+}
+
+func setInt(n *int, value string, minimum, maximum int) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", value)
+	}
+	if parsed < minimum || parsed > maximum {
+		return fmt.Errorf("%d is not between %d and %d", parsed, minimum, maximum)
+	}
+	*n = parsed
+	return nil
+}
+
+func setBool(b *bool, value string) error {
+	switch strings.ToLower(value) {
+	case "on", "true", "yes", "1":
+		*b = true
+	case "off", "false", "no", "0":
+		*b = false
+	default:
+		return fmt.Errorf("%q is not on or off", value)
+	}
+	return nil
+}
+
+func formatBool(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// Settings returns a copy of the game's current settings.
+func (g *Game) Settings() Settings {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.settings
+}
+
+// Configure changes a setting from the configuration. Settings changed at
+// runtime with SetSetting take precedence and are left alone.
+func (g *Game) Configure(key, value string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, overridden := g.overrides[strings.ToLower(key)]; overridden {
+		// Still validate, so a bad config value is reported either way
+		probe := g.settings
+		return probe.Set(key, value)
+	}
+	if err := g.settings.Set(key, value); err != nil {
+		return err
+	}
+	g.Matcher = NewFuzzyMatcher(g.settings.AnswerStrictness)
+	return nil
+}
+
+// SetSetting changes a setting at runtime and saves it, so the change
+// survives a restart.
+func (g *Game) SetSetting(key, value string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.settings.Set(key, value); err != nil {
+		return err
+	}
+	g.Matcher = NewFuzzyMatcher(g.settings.AnswerStrictness)
+	key = strings.ToLower(key)
+	g.overrides[key], _ = g.settings.Get(key)
+	g.saveSettings()
+	return nil
+}
+
+// saveSettings writes the runtime overrides to the game's settings file.
+// It must be called with g.mu held.
+func (g *Game) saveSettings() {
+	bytes, err := json.MarshalIndent(g.overrides, "", "  ")
+	if err != nil {
+		log.Printf("Error marshalling settings: %v", err)
+		return
+	}
+	if err := writeFileAtomic(g.settingsPath, bytes); err != nil {
+		log.Printf("Error saving settings: %v", err)
+	}
+}
+
+// loadSettings applies the runtime overrides saved by an earlier run.
+func (g *Game) loadSettings() {
+	bytes, err := os.ReadFile(g.settingsPath) // #nosec G304
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading settings file: %v", err)
+		}
+		return
+	}
+	var saved map[string]string
+	if err := json.Unmarshal(bytes, &saved); err != nil {
+		log.Printf("Error unmarshalling settings: %v", err)
+		return
+	}
+	for key, value := range saved {
+		if err := g.settings.Set(key, value); err != nil {
+			log.Printf("Ignoring saved setting in %s: %v", g.settingsPath, err)
+			continue
+		}
+		g.overrides[strings.ToLower(key)] = value
+	}
+	g.Matcher = NewFuzzyMatcher(g.settings.AnswerStrictness)
+}
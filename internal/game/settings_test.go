@@ -0,0 +1,112 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"trebek/internal/question"
+)
+
+func TestSettingsSetGet(t *testing.T) {
+	s := DefaultSettings()
+	valid := []struct {
+		key, value, expected string
+	}{
+		{"question_timeout", "20s", "20s"},
+		{"QUESTION_TIMEOUT", "45", "45s"}, // Bare seconds, any case
+		{"question_interval", "2m", "2m0s"},
+		{"answer_delay", "3s", "3s"},
+		{"max_hints", "0", "0"},
+		{"skip_votes", "5", "5"},
+		{"default_clue_value", "2000", "2000"},
+		{"hint_penalty", "25", "25"},
+		{"daily_double_chance", "20", "20"},
+		{"team_mode", "on", "on"},
+		{"team_window", "0", "0s"},
+		{"duel_window", "15", "15s"},
+		{"announce_winners", "Month, day", "day month"},
+		{"announce_winners", "off", "off"},
+		{"answer_strictness", "Lenient", "lenient"},
+	}
+	for _, tt := range valid {
+		if err := s.Set(tt.key, tt.value); err != nil {
+			t.Errorf("Set(%s, %s) failed: %v", tt.key, tt.value, err)
+			continue
+		}
+		if got, _ := s.Get(tt.key); got != tt.expected {
+			t.Errorf("Get(%s) = %q after setting %q, expected %q", tt.key, got, tt.value, tt.expected)
+		}
+	}
+	if s.QuestionTimeout != 45*time.Second || s.AnswerStrictness != StrictnessLenient {
+		t.Errorf("Settings not applied: %+v", s)
+	}
+
+	before := s
+	invalid := []struct{ key, value string }{
+		{"question_timeout", "soon"},
+		{"question_timeout", "1s"},
+		{"skip_votes", "0"},
+		{"hint_penalty", "101"},
+		{"daily_double_chance", "-5"},
+		{"team_window", "3m"},
+		{"announce_winners", "week fortnight"},
+		{"max_hints", "three"},
+		{"answer_strictness", "fuzzy"},
+		{"color", "blue"},
+	}
+	for _, tt := range invalid {
+		if err := s.Set(tt.key, tt.value); err == nil {
+			t.Errorf("Expected Set(%s, %s) to fail", tt.key, tt.value)
+		}
+	}
+	if s != before {
+		t.Errorf("Invalid values changed the settings: %+v", s)
+	}
+	if _, err := s.Get("color"); err == nil {
+		t.Error("Expected an error for an unknown setting")
+	}
+}
+
+func TestGameSettingsPersist(t *testing.T) {
+	originalSettingsFile := settingsFile
+	settingsFile = filepath.Join(t.TempDir(), "settings.json")
+	defer func() { settingsFile = originalSettingsFile }()
+
+	game := NewGame(newMockQuestionSource(nil), "#trivia")
+	if err := game.SetSetting("skip_votes", "2"); err != nil {
+		t.Fatalf("SetSetting failed: %v", err)
+	}
+	if err := game.SetSetting("answer_strictness", "exact"); err != nil {
+		t.Fatalf("SetSetting failed: %v", err)
+	}
+	if err := game.SetSetting("skip_votes", "-1"); err == nil {
+		t.Error("Expected an invalid value to be rejected")
+	}
+	game.CurrentQuestion = &question.Question{Answer: "Mississippi"}
+	if game.CheckAnswer("Missisippi") {
+		t.Error("Expected exact strictness to reject a typo")
+	}
+
+	// A new game for the same channel picks up the saved changes, which
+	// win over the configuration.
+	reloaded := NewGame(newMockQuestionSource(nil), "#Trivia")
+	if err := reloaded.Configure("skip_votes", "4"); err != nil {
+		t.Errorf("Configure failed: %v", err)
+	}
+	if err := reloaded.Configure("hint_penalty", "50"); err != nil {
+		t.Errorf("Configure failed: %v", err)
+	}
+	if err := reloaded.Configure("skip_votes", "many"); err == nil {
+		t.Error("Expected an invalid config value to be reported even when overridden")
+	}
+	settings := reloaded.Settings()
+	if settings.SkipVotes != 2 || settings.AnswerStrictness != StrictnessExact || settings.HintPenalty != 50 {
+		t.Errorf("Unexpected settings after reload: %+v", settings)
+	}
+
+	other := NewGame(newMockQuestionSource(nil), "#other")
+	if other.Settings() != DefaultSettings() {
+		t.Errorf("Settings leaked into another channel: %+v", other.Settings())
+	}
+}
@@ -0,0 +1,200 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"trebek/internal/question"
+)
+
+// maxTeamName caps the length of a team name.
+const maxTeamName = 30
+
+// Team is a group of players who score together in team mode.
+type Team struct {
+	Members []string `json:"members"`
+	Score   int      `json:"score"`
+}
+
+// validTeamName checks that a team name is short and made of letters,
+// digits, spaces, dashes and underscores.
+func validTeamName(name string) error {
+	if name == "" || utf8.RuneCountInString(name) > maxTeamName {
+		return fmt.Errorf("a team name must have between 1 and %d characters", maxTeamName)
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && r != '-' && r != '_' {
+			return errors.New("a team name may only have letters, digits, spaces, dashes and underscores")
+		}
+	}
+	return nil
+}
+
+// findTeam returns the name a team was created with, matching name in any
+// case, or "" if there is no such team. It must be called with sb.mu held.
+func (sb *Scoreboard) findTeam(name string) string {
+	for team := range sb.teams {
+		if strings.EqualFold(team, name) {
+			return team
+		}
+	}
+	return ""
+}
+
+// teamOf returns the team player is on, or "". It must be called with sb.mu held.
+func (sb *Scoreboard) teamOf(player string) string {
+	for name, team := range sb.teams {
+		for _, member := range team.Members {
+			if member == player {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// leave takes player off their team, dropping the team once it is empty. It
+// must be called with sb.mu held.
+func (sb *Scoreboard) leave(player string) string {
+	name := sb.teamOf(player)
+	if name == "" {
+		return ""
+	}
+	team := sb.teams[name]
+	for i, member := range team.Members {
+		if member == player {
+			team.Members = append(team.Members[:i], team.Members[i+1:]...)
+			break
+		}
+	}
+	if len(team.Members) == 0 {
+		delete(sb.teams, name)
+	}
+	return name
+}
+
+// CreateTeam starts a new team with player as its first member, taking them
+// off any team they were on.
+func (sb *Scoreboard) CreateTeam(name, player string) error {
+	name = strings.Join(strings.Fields(name), " ")
+	if err := validTeamName(name); err != nil {
+		return err
+	}
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if existing := sb.findTeam(name); existing != "" {
+		return fmt.Errorf("there is already a team called %s", existing)
+	}
+	sb.record(ScoreEvent{Type: eventCreateTeam, Player: player, Team: name})
+	return nil
+}
+
+// JoinTeam puts player on an existing team, taking them off any team they
+// were on. It returns the team's name as it was created.
+func (sb *Scoreboard) JoinTeam(name, player string) (string, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	team := sb.findTeam(strings.Join(strings.Fields(name), " "))
+	if team == "" {
+		return "", fmt.Errorf("there is no team called %s", name)
+	}
+	if sb.teamOf(player) == team {
+		return "", fmt.Errorf("you are already on %s", team)
+	}
+	sb.record(ScoreEvent{Type: eventJoinTeam, Player: player, Team: team})
+	return team, nil
+}
+
+// LeaveTeam takes player off their team and returns its name. A team is
+// disbanded, with its score, when its last member leaves.
+func (sb *Scoreboard) LeaveTeam(player string) (string, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	name := sb.teamOf(player)
+	if name == "" {
+		return "", errors.New("you are not on a team")
+	}
+	sb.record(ScoreEvent{Type: eventLeaveTeam, Player: player, Team: name})
+	return name, nil
+}
+
+// TeamOf returns the team player is on, or "".
+func (sb *Scoreboard) TeamOf(player string) string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.teamOf(player)
+}
+
+// Team returns a copy of the named team and true, or false if there is no
+// such team.
+func (sb *Scoreboard) Team(name string) (Team, bool) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	team, ok := sb.teams[sb.findTeam(name)]
+	if !ok {
+		return Team{}, false
+	}
+	c := *team
+	c.Members = append([]string(nil), team.Members...)
+	sort.Strings(c.Members)
+	return c, true
+}
+
+// TeamScores returns every team's score, by team name.
+func (sb *Scoreboard) TeamScores() map[string]int {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	scores := make(map[string]int, len(sb.teams))
+	for name, team := range sb.teams {
+		scores[name] = team.Score
+	}
+	return scores
+}
+
+// AddTeamScore adds points to a team's score.
+func (sb *Scoreboard) AddTeamScore(name string, points int) {
+	sb.AddTeamScoreFor(name, points, "", "")
+}
+
+// AddTeamScoreFor adds points to a team's score, recording why and the ID of
+// the question they were for, like AddScoreFor.
+func (sb *Scoreboard) AddTeamScoreFor(name string, points int, reason, questionID string) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	team := sb.findTeam(name)
+	if team == "" {
+		return
+	}
+	sb.record(ScoreEvent{Type: eventTeamScore, Team: team, Delta: points, Reason: reason, Question: questionID})
+}
+
+// TeamAnswer records a correct answer to the current question by player
+// while its team window is open, or opens the window if the answer is the
+// first. It returns the question answered and the side the answer counts
+// for, which is the player's team or, if they have none, the player. Only a
+// side's first correct answer counts; later ones return an error.
+func (g *Game) TeamAnswer(player string) (q *question.Question, side string, first bool, err error) {
+	side = g.Scoreboard.TeamOf(player)
+	if side == "" {
+		side = player
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	q = g.CurrentQuestion
+	if q == nil {
+		return nil, side, false, errors.New("the question is closed")
+	}
+	if g.teamAnswers == nil {
+		g.teamAnswers = make(map[string]bool)
+		first = true
+	}
+	if g.teamAnswers[side] {
+		return q, side, false, fmt.Errorf("%s already scored on this question", side)
+	}
+	g.teamAnswers[side] = true
+	return q, side, first, nil
+}
@@ -0,0 +1,113 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"trebek/internal/question"
+)
+
+func TestTeams(t *testing.T) {
+	originalScoreboardFile := scoreboardFile
+	scoreboardFile = filepath.Join(t.TempDir(), "scoreboard.json")
+	defer func() { scoreboardFile = originalScoreboardFile }()
+
+	sb := NewChannelScoreboard("#trivia")
+	if err := sb.CreateTeam("  Human   Resources ", "alice"); err != nil {
+		t.Fatalf("CreateTeam failed: %v", err)
+	}
+	for _, name := range []string{"", "human resources", "Sales!", "A team name that goes on and on and on"} {
+		if err := sb.CreateTeam(name, "bob"); err == nil {
+			t.Errorf("Expected CreateTeam(%q) to fail", name)
+		}
+	}
+	if err := sb.CreateTeam("Sales", "carol"); err != nil {
+		t.Fatalf("CreateTeam failed: %v", err)
+	}
+	if team, err := sb.JoinTeam("HUMAN resources", "bob"); err != nil || team != "Human Resources" {
+		t.Errorf("JoinTeam = %q, %v", team, err)
+	}
+	if _, err := sb.JoinTeam("Human Resources", "bob"); err == nil {
+		t.Error("Expected joining the same team twice to fail")
+	}
+	if _, err := sb.JoinTeam("Marketing", "bob"); err == nil {
+		t.Error("Expected joining a missing team to fail")
+	}
+
+	// Moving to another team leaves the old one, which disbands once empty
+	if _, err := sb.JoinTeam("Human Resources", "carol"); err != nil {
+		t.Fatalf("JoinTeam failed: %v", err)
+	}
+	if _, ok := sb.Team("Sales"); ok {
+		t.Error("Expected the empty team to be disbanded")
+	}
+	sb.AddTeamScore("human resources", 400)
+	if team, _ := sb.Team("Human Resources"); team.Score != 400 || len(team.Members) != 3 || team.Members[0] != "alice" {
+		t.Errorf("Unexpected team: %+v", team)
+	}
+	if left, err := sb.LeaveTeam("bob"); err != nil || left != "Human Resources" {
+		t.Errorf("LeaveTeam = %q, %v", left, err)
+	}
+	if _, err := sb.LeaveTeam("bob"); err == nil {
+		t.Error("Expected leaving without a team to fail")
+	}
+
+	// Teams are saved with the scores and keep their members over a reset
+	sb.AddScore("alice", 100)
+	reloaded := NewChannelScoreboard("#trivia")
+	if reloaded.GetScore("alice") != 100 || reloaded.TeamOf("carol") != "Human Resources" || reloaded.TeamScores()["Human Resources"] != 400 {
+		t.Errorf("Teams not reloaded: %v", reloaded.TeamScores())
+	}
+	reloaded.Reset()
+	if team, ok := reloaded.Team("Human Resources"); !ok || team.Score != 0 || len(team.Members) != 2 {
+		t.Errorf("Unexpected team after reset: %+v", team)
+	}
+}
+
+func TestScoreboardLoadsScoresWithoutTeams(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scoreboard.json")
+	if err := os.WriteFile(path, []byte(`{"alice": 5, "scores": 7}`), 0600); err != nil {
+		t.Fatalf("Failed to write scoreboard: %v", err)
+	}
+	sb := newScoreboardAt(path)
+	if sb.GetScore("alice") != 5 || sb.GetScore("scores") != 7 || sb.teams == nil {
+		t.Errorf("Expected scores saved before teams to load, got %v", sb.Scores())
+	}
+}
+
+func TestTeamScoring(t *testing.T) {
+	originalScoreboardFile := scoreboardFile
+	scoreboardFile = filepath.Join(t.TempDir(), "scoreboard.json")
+	defer func() { scoreboardFile = originalScoreboardFile }()
+
+	game := NewGame(newMockQuestionSource([]*question.Question{
+		{Category: "RIVERS", Question: "It flows through Cairo", Answer: "the Nile", Money: "$400"},
+	}), "#trivia")
+	game.Scoreboard.CreateTeam("Sales", "alice")
+	game.Scoreboard.JoinTeam("Sales", "bob")
+
+	game.AwardCorrect("alice", 400)
+	if score := game.Scoreboard.TeamScores()["Sales"]; score != 0 {
+		t.Errorf("Expected no team score outside team mode, got %d", score)
+	}
+	game.Configure("team_mode", "on")
+	game.AwardCorrect("alice", 400)
+	if score := game.Scoreboard.TeamScores()["Sales"]; score != 400 || game.Scoreboard.GetScore("alice") != 800 {
+		t.Errorf("Expected the answer to count for alice and Sales, got %d", score)
+	}
+
+	if _, _, _, err := game.TeamAnswer("alice"); err == nil {
+		t.Error("Expected no team answers without a question")
+	}
+	game.StartRound()
+	if _, side, first, err := game.TeamAnswer("bob"); side != "Sales" || !first || err != nil {
+		t.Errorf("TeamAnswer(bob) = %q, %v, %v", side, first, err)
+	}
+	if _, _, _, err := game.TeamAnswer("alice"); err == nil {
+		t.Error("Expected a team's second answer not to count")
+	}
+	if _, side, first, err := game.TeamAnswer("carol"); side != "carol" || first || err != nil {
+		t.Errorf("TeamAnswer(carol) = %q, %v, %v", side, first, err)
+	}
+}
@@ -0,0 +1,50 @@
+package irc
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff computes jittered exponential delays between reconnect attempts.
+type Backoff struct {
+	Min     time.Duration // Delay before the first retry
+	Max     time.Duration // Upper bound for any delay
+	Factor  float64       // Multiplier applied after each attempt
+	attempt int
+	rand    *rand.Rand
+}
+
+// NewBackoff creates a Backoff that doubles from min up to max.
+func NewBackoff(min, max time.Duration) *Backoff {
+	return &Backoff{
+		Min:    min,
+		Max:    max,
+		Factor: 2,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())), // #nosec G404
+	}
+}
+
+// Next returns the delay to wait before the next attempt. Half of the delay is
+// fixed and the other half is random, so many clients reconnecting after the
+// same outage do not all hit the server at once.
+func (b *Backoff) Next() time.Duration {
+	d := float64(b.Min)
+	for i := 0; i < b.attempt && d < float64(b.Max); i++ {
+		d *= b.Factor
+	}
+	if d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	b.attempt++
+
+	half := time.Duration(d / 2)
+	if half <= 0 {
+		return time.Duration(d)
+	}
+	return half + time.Duration(b.rand.Int63n(int64(half)+1))
+}
+
+// Reset starts the delay sequence over, typically after a stable connection.
+func (b *Backoff) Reset() {
+	b.attempt = 0
+}
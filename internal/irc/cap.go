@@ -0,0 +1,175 @@
+package irc
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// wantedCaps are the IRCv3 capabilities requested when the server offers them.
+// "sasl" is only requested when a SASL mechanism is configured.
+var wantedCaps = []string{
+	"message-tags",
+	"server-time",
+	"account-tag",
+	"account-notify",
+	"extended-join",
+	"multi-prefix",
+	"chghost",
+}
+
+// saslChunkSize is the maximum length of a single AUTHENTICATE payload line.
+const saslChunkSize = 400
+
+// ErrSASLUnavailable is returned by Register when SASL is configured but the server does not offer it.
+var ErrSASLUnavailable = errors.New("registration failed: server does not support SASL")
+
+// HasCap reports whether a capability was acknowledged by the server.
+func (c *Client) HasCap(name string) bool {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	return c.caps[name]
+}
+
+// saslMechanism returns the configured SASL mechanism in upper case, or "" if SASL is off.
+func (c *Client) saslMechanism() string {
+	return strings.ToUpper(strings.TrimSpace(c.Config.SASLMechanism))
+}
+
+// negotiate handles CAP and SASL traffic during registration.
+// It returns an error when authentication fails and the connection should be abandoned.
+func (c *Client) negotiate(msg *Message) error {
+	switch msg.Command {
+	case "CAP":
+		return c.handleCap(msg)
+	case "AUTHENTICATE":
+		if msg.Param(0) == "+" {
+			c.sendSASLResponse()
+		}
+	case RPL_LOGGEDIN:
+		log.Printf("Logged in as %s", msg.Param(2))
+	case RPL_SASLSUCCESS:
+		c.Send("CAP END")
+	case ERR_NICKLOCKED, ERR_SASLFAIL, ERR_SASLTOOLONG, ERR_SASLABORTED, RPL_SASLMECHS:
+		c.Send("CAP END")
+		return &RegistrationError{Numeric: msg.Command, Reason: msg.Trailing()}
+	}
+	return nil
+}
+
+// handleCap processes CAP LS, ACK and NAK replies.
+func (c *Client) handleCap(msg *Message) error {
+	subcommand := strings.ToUpper(msg.Param(1))
+	switch subcommand {
+	case "LS":
+		// Multi-line replies carry "*" before the final parameter.
+		more := len(msg.Params) > 3 && msg.Param(2) == "*"
+		c.stateMu.Lock()
+		for _, token := range strings.Fields(msg.Trailing()) {
+			name, value, _ := strings.Cut(token, "=")
+			c.capsOffered[name] = value
+		}
+		offered := c.capsOffered
+		c.stateMu.Unlock()
+		if more {
+			return nil
+		}
+		return c.requestCaps(offered)
+
+	case "ACK":
+		c.stateMu.Lock()
+		for _, name := range strings.Fields(msg.Trailing()) {
+			if strings.HasPrefix(name, "-") {
+				delete(c.caps, name[1:])
+				continue
+			}
+			c.caps[name] = true
+		}
+		sasl := c.caps["sasl"]
+		c.stateMu.Unlock()
+
+		if sasl && c.saslMechanism() != "" {
+			c.Send("AUTHENTICATE %s", c.saslMechanism())
+			return nil
+		}
+		c.Send("CAP END")
+
+	case "NAK":
+		c.Send("CAP END")
+		if c.saslMechanism() != "" && strings.Contains(" "+msg.Trailing()+" ", " sasl ") {
+			return ErrSASLUnavailable
+		}
+	}
+	return nil
+}
+
+// requestCaps sends CAP REQ for the wanted capabilities the server offers,
+// or ends negotiation if there is nothing to request.
+func (c *Client) requestCaps(offered map[string]string) error {
+	var req []string
+	for _, name := range wantedCaps {
+		if _, ok := offered[name]; ok {
+			req = append(req, name)
+		}
+	}
+
+	if mech := c.saslMechanism(); mech != "" {
+		mechs, ok := offered["sasl"]
+		if !ok {
+			c.Send("CAP END")
+			return ErrSASLUnavailable
+		}
+		// With CAP 302 the server may list its mechanisms; an empty list means "ask me".
+		if mechs != "" && !containsFold(strings.Split(mechs, ","), mech) {
+			c.Send("CAP END")
+			return fmt.Errorf("registration failed: server does not support SASL %s (offers %s)", mech, mechs)
+		}
+		req = append(req, "sasl")
+	}
+
+	if len(req) == 0 {
+		c.Send("CAP END")
+		return nil
+	}
+	c.Send("CAP REQ :%s", strings.Join(req, " "))
+	return nil
+}
+
+// sendSASLResponse answers the server's AUTHENTICATE challenge for the configured mechanism.
+func (c *Client) sendSASLResponse() {
+	var payload []byte
+	switch c.saslMechanism() {
+	case "PLAIN":
+		user := c.Config.SASLUsername
+		payload = []byte(user + "\x00" + user + "\x00" + c.Config.SASLPassword)
+	case "EXTERNAL":
+		// The identity comes from the TLS client certificate.
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(payload)
+	if encoded == "" {
+		c.Send("AUTHENTICATE +")
+		return
+	}
+	for len(encoded) >= saslChunkSize {
+		c.Send("AUTHENTICATE %s", encoded[:saslChunkSize])
+		encoded = encoded[saslChunkSize:]
+	}
+	if encoded == "" {
+		c.Send("AUTHENTICATE +") // The last chunk was exactly saslChunkSize bytes long
+		return
+	}
+	c.Send("AUTHENTICATE %s", encoded)
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
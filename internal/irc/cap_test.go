@@ -0,0 +1,283 @@
+package irc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"trebek/internal/config"
+)
+
+const welcome = ":irc.example.com 001 TestBot :Welcome\r\n"
+
+// saslServer scripts a server that offers the given caps and accepts
+// any AUTHENTICATE payload for which accept returns true.
+func saslServer(offered string, accept func(payload string) bool) func(string) string {
+	return func(line string) string {
+		switch {
+		case line == "CAP LS 302":
+			return ":irc.example.com CAP * LS :" + offered + "\r\n"
+		case strings.HasPrefix(line, "CAP REQ :"):
+			return ":irc.example.com CAP * ACK :" + strings.TrimPrefix(line, "CAP REQ :") + "\r\n"
+		case line == "AUTHENTICATE PLAIN" || line == "AUTHENTICATE EXTERNAL":
+			return "AUTHENTICATE +\r\n"
+		case strings.HasPrefix(line, "AUTHENTICATE "):
+			if accept(strings.TrimPrefix(line, "AUTHENTICATE ")) {
+				return ":irc.example.com 900 TestBot TestBot!b@h trebek :You are now logged in as trebek\r\n" +
+					":irc.example.com 903 TestBot :SASL authentication successful\r\n"
+			}
+			return ":irc.example.com 904 TestBot :SASL authentication failed\r\n"
+		case line == "CAP END":
+			return welcome
+		}
+		return ""
+	}
+}
+
+func newScriptedClient(t *testing.T, cfg *config.Config, script func(string) string) (*Client, *MockConn) {
+	t.Helper()
+	mockConn := NewMockConn()
+	mockConn.Script(script)
+	mockDial = func(network, address string) (net.Conn, error) {
+		return mockConn, nil
+	}
+	t.Cleanup(func() { mockDial = nil })
+
+	client := NewClient(cfg)
+	if err := client.Connect(false); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	return client, mockConn
+}
+
+func TestCapNegotiation(t *testing.T) {
+	cfg := &config.Config{BotName: "TestBot", IRCServer: "irc.example.com:6667"}
+
+	t.Run("RequestsOfferedCaps", func(t *testing.T) {
+		client, mockConn := newScriptedClient(t, cfg, saslServer("sasl=PLAIN account-tag multi-prefix unknown-cap", nil))
+		defer client.Close()
+
+		if err := client.Register(); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		client.Flush(time.Second)
+		written := mockConn.GetWrittenData()
+		if !strings.Contains(written, "CAP REQ :account-tag multi-prefix\r\n") {
+			t.Errorf("Expected CAP REQ for wanted caps only, got %q", written)
+		}
+		if !strings.Contains(written, "CAP END\r\n") {
+			t.Errorf("Expected CAP END, got %q", written)
+		}
+		if !client.HasCap("account-tag") || client.HasCap("sasl") {
+			t.Errorf("Unexpected caps after ACK: account-tag=%t sasl=%t", client.HasCap("account-tag"), client.HasCap("sasl"))
+		}
+	})
+
+	t.Run("MultilineLS", func(t *testing.T) {
+		script := func(line string) string {
+			switch {
+			case line == "CAP LS 302":
+				return ":irc.example.com CAP * LS * :message-tags\r\n:irc.example.com CAP * LS :server-time\r\n"
+			case strings.HasPrefix(line, "CAP REQ :"):
+				return ":irc.example.com CAP * ACK :" + strings.TrimPrefix(line, "CAP REQ :") + "\r\n"
+			case line == "CAP END":
+				return welcome
+			}
+			return ""
+		}
+		client, mockConn := newScriptedClient(t, cfg, script)
+		defer client.Close()
+
+		if err := client.Register(); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		client.Flush(time.Second)
+		if !strings.Contains(mockConn.GetWrittenData(), "CAP REQ :message-tags server-time\r\n") {
+			t.Errorf("Expected a single CAP REQ after the last LS line, got %q", mockConn.GetWrittenData())
+		}
+	})
+
+	t.Run("NoCapSupport", func(t *testing.T) {
+		script := func(line string) string {
+			if line == "CAP LS 302" {
+				return ":irc.example.com 421 * CAP :Unknown command\r\n" + welcome
+			}
+			return ""
+		}
+		client, _ := newScriptedClient(t, cfg, script)
+		defer client.Close()
+
+		if err := client.Register(); err != nil {
+			t.Fatalf("Register failed against a server without CAP: %v", err)
+		}
+	})
+}
+
+func TestSASLPlain(t *testing.T) {
+	cfg := &config.Config{
+		BotName:       "TestBot",
+		IRCServer:     "irc.example.com:6667",
+		SASLMechanism: "plain",
+		SASLUsername:  "trebek",
+		SASLPassword:  "hunter2",
+	}
+	want := base64.StdEncoding.EncodeToString([]byte("trebek\x00trebek\x00hunter2"))
+
+	t.Run("Success", func(t *testing.T) {
+		client, mockConn := newScriptedClient(t, cfg, saslServer("sasl=PLAIN,EXTERNAL", func(p string) bool { return p == want }))
+		defer client.Close()
+
+		if err := client.Register(); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		client.Flush(time.Second)
+		written := mockConn.GetWrittenData()
+		for _, line := range []string{"CAP REQ :sasl", "AUTHENTICATE PLAIN", "AUTHENTICATE " + want, "CAP END"} {
+			if !strings.Contains(written, line+"\r\n") {
+				t.Errorf("Expected %q to be sent, got %q", line, written)
+			}
+		}
+	})
+
+	t.Run("BadPassword", func(t *testing.T) {
+		client, _ := newScriptedClient(t, cfg, saslServer("sasl", func(string) bool { return false }))
+		defer client.Close()
+
+		err := client.Register()
+		var regErr *RegistrationError
+		if !errors.As(err, &regErr) || regErr.Numeric != ERR_SASLFAIL {
+			t.Fatalf("Expected ERR_SASLFAIL registration error, got %v", err)
+		}
+		if !isFatal(err) {
+			t.Error("SASL failure should stop reconnect attempts")
+		}
+	})
+
+	t.Run("NotOffered", func(t *testing.T) {
+		client, _ := newScriptedClient(t, cfg, saslServer("multi-prefix", nil))
+		defer client.Close()
+
+		if err := client.Register(); !errors.Is(err, ErrSASLUnavailable) {
+			t.Fatalf("Expected ErrSASLUnavailable, got %v", err)
+		}
+	})
+
+	t.Run("MechanismNotOffered", func(t *testing.T) {
+		client, _ := newScriptedClient(t, cfg, saslServer("sasl=EXTERNAL", nil))
+		defer client.Close()
+
+		err := client.Register()
+		if err == nil || !strings.Contains(err.Error(), "PLAIN") {
+			t.Fatalf("Expected unsupported mechanism error, got %v", err)
+		}
+	})
+}
+
+func TestSASLResponseChunking(t *testing.T) {
+	cfg := &config.Config{
+		BotName:       "TestBot",
+		SASLMechanism: "PLAIN",
+		SASLUsername:  "u",
+		// 3 + 2 NULs + 295 = 300 raw bytes encode to exactly 400 base64 characters.
+		SASLPassword: strings.Repeat("p", 295),
+	}
+	client, mockConn := newScriptedClient(t, cfg, nil)
+	defer client.Close()
+	client.Flush(time.Second)
+	mockConn.ClearWrittenData()
+
+	client.sendSASLResponse()
+	client.Flush(time.Second)
+	lines := strings.Split(strings.TrimSpace(mockConn.GetWrittenData()), "\r\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 AUTHENTICATE lines, got %d: %q", len(lines), lines)
+	}
+	if len(strings.TrimPrefix(lines[0], "AUTHENTICATE ")) != saslChunkSize {
+		t.Errorf("First chunk should be %d bytes, got %q", saslChunkSize, lines[0])
+	}
+	if lines[1] != "AUTHENTICATE +" {
+		t.Errorf("Expected terminating AUTHENTICATE +, got %q", lines[1])
+	}
+}
+
+func TestSASLExternal(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+	cfg := &config.Config{
+		BotName:       "TestBot",
+		IRCServerTLS:  "irc.example.com:6697",
+		SASLMechanism: "EXTERNAL",
+		TLSClientCert: certFile,
+		TLSClientKey:  keyFile,
+	}
+
+	mockConn := NewMockConn()
+	mockConn.Script(saslServer("sasl=EXTERNAL", func(p string) bool { return p == "+" }))
+	mockTLSDial = func(network, address string, tlsConfig *tls.Config) (net.Conn, error) {
+		if len(tlsConfig.Certificates) != 1 {
+			t.Errorf("Expected the client certificate in the TLS config, got %d certificates", len(tlsConfig.Certificates))
+		}
+		return mockConn, nil
+	}
+	defer func() { mockTLSDial = nil }()
+
+	client := NewClient(cfg)
+	if err := client.Connect(true); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Register(); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	client.Flush(time.Second)
+	if !strings.Contains(mockConn.GetWrittenData(), "AUTHENTICATE EXTERNAL\r\nAUTHENTICATE +\r\n") {
+		t.Errorf("Expected EXTERNAL exchange, got %q", mockConn.GetWrittenData())
+	}
+}
+
+// writeTestCertificate creates a self-signed certificate and key in a temp dir.
+func writeTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "trebek"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return certFile, keyFile
+}
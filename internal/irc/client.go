@@ -3,10 +3,13 @@ package irc
 import (
 	"bufio"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"strings"
+	"sync"
+	"time"
 
 	"trebek/internal/config"
 )
@@ -19,19 +22,75 @@ var (
 	}
 )
 
+const (
+	defaultReconnectMin        = 1 * time.Second
+	defaultReconnectMax        = 5 * time.Minute
+	defaultRegistrationTimeout = 30 * time.Second
+	maxPendingLines            = 50 // Messages kept while waiting for registration
+	quitFlushTimeout           = 2 * time.Second
+)
+
 // Client represents an IRC client.
 type Client struct {
 	conn    net.Conn
 	reader  *bufio.Reader
-	writer  *bufio.Writer
+	queue   *sendQueue // Outgoing lines, drained by a single writer goroutine per connection
+	connMu  sync.Mutex // Guards conn, reader and queue across reconnects
 	Config  *config.Config
-	Handler func(string, string, string) // Callback for messages: channel, user, message
+	Handler func(*Message) // Callback for every parsed message from the server
+
+	OnConnect    func()      // Called once the bot is back in its channels after (re)connecting
+	OnDisconnect func(error) // Called when the connection is lost
+
+	ReconnectMin        time.Duration // Initial delay between reconnect attempts
+	ReconnectMax        time.Duration // Maximum delay between reconnect attempts
+	RegistrationTimeout time.Duration // How long Register waits for RPL_WELCOME
+	NickRegainInterval  time.Duration // How often to try for the primary nick while using an alternate
+	FloodBurst          int           // Lines that may be sent back to back
+	FloodRate           float64       // Sustained lines per second after the burst
+
+	primaryNick string        // The configured BOT_NAME we always try to get back
+	nick        string        // The nick currently in use (or being attempted)
+	nickAttempt int           // Index into nickCandidates during registration
+	user, host  string        // Our user and host as seen by others, once known
+	connDone    chan struct{} // Closed when the current connection is closed
+
+	registered  bool              // True once RPL_WELCOME has been received on this connection
+	pending     []string          // Lines queued until registration completes
+	caps        map[string]bool   // IRCv3 capabilities acknowledged by the server
+	capsOffered map[string]string // Capabilities advertised in CAP LS, with their values
+	stateMu     sync.Mutex
+
+	users *userTracker // Who is in our channels, with their status and accounts
+
+	channels   map[string]struct{} // Channels to (re)join, keyed by lowercased name
+	channelsMu sync.Mutex
+	quit       chan struct{}
+	quitOnce   sync.Once
 }
 
 // NewClient creates a new IRC client.
 func NewClient(cfg *config.Config) *Client {
+	burst, rate := cfg.FloodBurst, cfg.FloodRate
+	if burst <= 0 {
+		burst = defaultFloodBurst
+	}
+	if rate <= 0 {
+		rate = defaultFloodRate
+	}
 	return &Client{
-		Config: cfg,
+		Config:              cfg,
+		ReconnectMin:        defaultReconnectMin,
+		ReconnectMax:        defaultReconnectMax,
+		RegistrationTimeout: defaultRegistrationTimeout,
+		NickRegainInterval:  defaultNickRegainInterval,
+		FloodBurst:          burst,
+		FloodRate:           rate,
+		primaryNick:         cfg.BotName,
+		nick:                cfg.BotName,
+		users:               newUserTracker(),
+		channels:            make(map[string]struct{}),
+		quit:                make(chan struct{}),
 	}
 }
 
@@ -44,7 +103,15 @@ func (c *Client) Connect(useTLS bool) error {
 	if useTLS {
 		serverAddr = c.Config.IRCServerTLS
 		log.Printf("Connecting to IRC server (TLS): %s", serverAddr)
-		conn, err = tlsDial("tcp", serverAddr, &tls.Config{InsecureSkipVerify: true}) // #nosec G402 - InsecureSkipVerify for simplicity, should be false in production
+		tlsConfig := &tls.Config{InsecureSkipVerify: true} // #nosec G402 - InsecureSkipVerify for simplicity, should be false in production
+		if c.Config.TLSClientCert != "" {
+			cert, certErr := tls.LoadX509KeyPair(c.Config.TLSClientCert, c.Config.TLSClientKey)
+			if certErr != nil {
+				return fmt.Errorf("failed to load TLS client certificate: %w", certErr)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		conn, err = tlsDial("tcp", serverAddr, tlsConfig)
 	} else {
 		log.Printf("Connecting to IRC server (non-TLS): %s", serverAddr)
 		conn, err = netDial("tcp", serverAddr)
@@ -54,46 +121,306 @@ func (c *Client) Connect(useTLS bool) error {
 		return fmt.Errorf("failed to connect to IRC server: %w", err)
 	}
 
+	queue := newSendQueue()
+	done := make(chan struct{})
+
+	c.connMu.Lock()
 	c.conn = conn
 	c.reader = bufio.NewReader(conn)
-	c.writer = bufio.NewWriter(conn)
+	c.queue = queue
+	c.connMu.Unlock()
+
+	c.stateMu.Lock()
+	c.registered = false
+	c.caps = make(map[string]bool)
+	c.capsOffered = make(map[string]string)
+	c.nickAttempt = 0
+	c.user, c.host = "", ""
+	c.connDone = done
+	c.stateMu.Unlock()
+	c.users.reset()
+
+	go c.writeLoop(queue, bufio.NewWriter(conn), done)
+	c.setNick(c.primaryNick)
 
 	log.Printf("Connected to %s", serverAddr)
 
-	// Send NICK and USER commands
-	c.Send("NICK %s", c.Config.BotName)
-	c.Send("USER %s 0 * :%s", c.Config.BotName, c.Config.BotName)
+	// Start capability negotiation, then send NICK and USER commands.
+	// Servers without IRCv3 support simply ignore CAP.
+	c.Send("CAP LS 302")
+	c.Send("NICK %s", c.primaryNick)
+	c.Send("USER %s 0 * :%s", c.primaryNick, c.primaryNick)
 
 	return nil
 }
 
-// Send sends a raw IRC command to the server.
+// Register blocks until the server accepts the connection with RPL_WELCOME,
+// negotiating IRCv3 capabilities and SASL authentication along the way.
+// It fails with a *RegistrationError if the server rejects the nick or credentials,
+// or with a read error if the connection drops or RegistrationTimeout passes first.
+func (c *Client) Register() error {
+	c.connMu.Lock()
+	conn, reader := c.conn, c.reader
+	c.connMu.Unlock()
+	if conn == nil {
+		return fmt.Errorf("registration failed: not connected")
+	}
+
+	if c.RegistrationTimeout > 0 {
+		if err := conn.SetReadDeadline(time.Now().Add(c.RegistrationTimeout)); err != nil {
+			return fmt.Errorf("registration failed: %w", err)
+		}
+		defer func() {
+			if err := conn.SetReadDeadline(time.Time{}); err != nil {
+				log.Printf("Error clearing read deadline: %v", err)
+			}
+		}()
+	}
+
+	for {
+		msg, err := c.readMessage(reader)
+		if err != nil {
+			return fmt.Errorf("registration failed: %w", err)
+		}
+		if err := c.negotiate(msg); err != nil {
+			return err
+		}
+		c.dispatch(msg)
+
+		if msg.Command == RPL_WELCOME {
+			return nil
+		}
+		if (msg.Command == ERR_NICKNAMEINUSE || msg.Command == ERR_NICKCOLLISION) && c.tryNextNick() {
+			continue
+		}
+		if registrationFailures[msg.Command] {
+			return &RegistrationError{Numeric: msg.Command, Reason: msg.Trailing()}
+		}
+	}
+}
+
+// Run connects to the server and keeps the connection alive until Quit is called.
+// Whenever the connection drops it reconnects with jittered exponential backoff,
+// registers again and rejoins every channel passed to JoinChannel.
+// It returns early only if the server refuses registration in a way retrying cannot fix.
+func (c *Client) Run(useTLS bool) error {
+	backoff := NewBackoff(c.ReconnectMin, c.ReconnectMax)
+	for {
+		connectedAt := time.Now()
+		err := c.Connect(useTLS)
+		if err == nil {
+			err = c.Register()
+			if isFatal(err) {
+				c.Close()
+				return err
+			}
+		}
+		if err == nil {
+			if c.OnConnect != nil {
+				c.OnConnect()
+			}
+			err = c.Listen()
+			if c.OnDisconnect != nil && !c.quitting() {
+				c.OnDisconnect(err)
+			}
+			// Only start the backoff over if the connection was stable for a while.
+			if time.Since(connectedAt) > c.ReconnectMax {
+				backoff.Reset()
+			}
+		} else {
+			c.Close()
+		}
+
+		if c.quitting() {
+			return nil
+		}
+
+		delay := backoff.Next()
+		log.Printf("Disconnected from IRC (%v), reconnecting in %s", err, delay)
+		select {
+		case <-c.quit:
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isFatal reports whether a registration error means reconnecting is pointless.
+func isFatal(err error) bool {
+	if errors.Is(err, ErrSASLUnavailable) {
+		return true
+	}
+	var regErr *RegistrationError
+	return errors.As(err, &regErr) && !regErr.Retryable()
+}
+
+// onWelcome marks the connection as registered, joins the remembered channels
+// and flushes anything queued while registration was in progress.
+func (c *Client) onWelcome(nick string) {
+	c.setNick(nick)
+
+	c.stateMu.Lock()
+	c.registered = true
+	pending := c.pending
+	c.pending = nil
+	done := c.connDone
+	c.stateMu.Unlock()
+
+	if !c.IsMe(c.primaryNick) {
+		c.ghostPrimaryNick()
+		go c.watchNick(done)
+	}
+
+	for _, channel := range c.Channels() {
+		c.Send("JOIN %s", channel)
+	}
+	for _, line := range pending {
+		c.Send("%s", line)
+	}
+}
+
+// Registered reports whether the current connection has completed registration.
+func (c *Client) Registered() bool {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	return c.registered
+}
+
+// sendRegistered sends a line that is only valid after registration, queueing it
+// until RPL_WELCOME if necessary.
+func (c *Client) sendRegistered(line string) {
+	c.stateMu.Lock()
+	if !c.registered {
+		if len(c.pending) < maxPendingLines {
+			c.pending = append(c.pending, line)
+		} else {
+			log.Printf("Pending queue full, dropping: %s", line)
+		}
+		c.stateMu.Unlock()
+		return
+	}
+	c.stateMu.Unlock()
+	c.Send("%s", line)
+}
+
+// Send queues a raw IRC command for the writer goroutine. It is safe to call
+// from any goroutine. Keepalive and registration commands jump the queue;
+// everything else is rate limited to avoid being disconnected for flooding.
 func (c *Client) Send(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	log.Printf("--> %s", msg)
-	_, err := fmt.Fprintf(c.writer, "%s\r\n", msg)
-	if err != nil {
-		log.Printf("Error writing to IRC: %v", err)
+
+	c.connMu.Lock()
+	queue := c.queue
+	c.connMu.Unlock()
+	if queue == nil {
+		log.Printf("Not connected, dropping: %s", msg)
 		return
 	}
-	err = c.writer.Flush()
-	if err != nil {
-		log.Printf("Error flushing writer: %v", err)
+	if !queue.push(msg, priorityFor(msg)) {
+		log.Printf("Send queue closed or full, dropping: %s", msg)
+	}
+}
+
+// Flush waits until everything queued so far has been written, or the timeout passes.
+// It reports whether the queue drained.
+func (c *Client) Flush(timeout time.Duration) bool {
+	c.connMu.Lock()
+	queue := c.queue
+	c.connMu.Unlock()
+	if queue == nil {
+		return true
+	}
+	return queue.waitEmpty(timeout)
+}
+
+// writeLoop is the only goroutine that writes to the connection. It sends
+// queued lines in priority order and holds normal lines back while the token
+// bucket is empty, letting high-priority lines overtake them.
+func (c *Client) writeLoop(queue *sendQueue, w *bufio.Writer, done <-chan struct{}) {
+	bucket := newTokenBucket(c.FloodBurst, c.FloodRate)
+	for {
+		line, priority, ok := queue.pop()
+		if !ok {
+			return
+		}
+
+		if priority == PriorityHigh {
+			bucket.takeNoWait()
+		} else if wait := bucket.take(); wait > 0 {
+			queue.requeueFront(line)
+			select {
+			case <-done:
+				return
+			case <-queue.highReady:
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		log.Printf("--> %s", line)
+		_, err := w.WriteString(line + "\r\n")
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			log.Printf("Error writing to IRC: %v", err)
+		}
+		queue.done()
 	}
 }
 
-// JoinChannel joins the specified IRC channel.
+// JoinChannel joins the specified IRC channel and remembers it so it is
+// rejoined after a reconnect. Before registration the join is deferred until RPL_WELCOME.
 func (c *Client) JoinChannel(channel string) {
-	c.Send("JOIN %s", channel)
+	c.channelsMu.Lock()
+	c.channels[strings.ToLower(channel)] = struct{}{}
+	c.channelsMu.Unlock()
+	if c.Registered() {
+		c.Send("JOIN %s", channel)
+	}
+}
+
+// Channels returns the channels the client will rejoin after a reconnect.
+func (c *Client) Channels() []string {
+	c.channelsMu.Lock()
+	defer c.channelsMu.Unlock()
+	channels := make([]string, 0, len(c.channels))
+	for ch := range c.channels {
+		channels = append(channels, ch)
+	}
+	return channels
+}
+
+// forgetChannel stops the channel from being rejoined after a reconnect.
+func (c *Client) forgetChannel(channel string) {
+	c.channelsMu.Lock()
+	delete(c.channels, strings.ToLower(channel))
+	c.channelsMu.Unlock()
 }
 
-// Privmsg sends a private message to a target (channel or user).
+// Privmsg sends a private message to a target (channel or user). Messages too
+// long for one IRC line are split over several.
 func (c *Client) Privmsg(target, message string) {
-	c.Send("PRIVMSG %s :%s", target, message)
+	for _, line := range splitMessage(message, c.messageRoom("PRIVMSG", target)) {
+		c.sendRegistered(fmt.Sprintf("PRIVMSG %s :%s", target, line))
+	}
 }
 
 // Close closes the IRC client connection.
 func (c *Client) Close() {
+	c.stateMu.Lock()
+	c.registered = false
+	if c.connDone != nil {
+		close(c.connDone)
+		c.connDone = nil
+	}
+	c.stateMu.Unlock()
+
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	if c.queue != nil {
+		c.queue.close()
+	}
 	if c.conn != nil {
 		err := c.conn.Close()
 		if err != nil {
@@ -103,55 +430,105 @@ func (c *Client) Close() {
 	}
 }
 
-// Listen starts listening for messages from the IRC server.
-func (c *Client) Listen() {
+// Quit sends QUIT, closes the connection and stops Run from reconnecting.
+func (c *Client) Quit(reason string) {
+	c.quitOnce.Do(func() {
+		close(c.quit)
+	})
+	c.Send("QUIT :%s", reason)
+	c.Flush(quitFlushTimeout)
+	c.Close()
+}
+
+// quitting reports whether Quit has been called.
+func (c *Client) quitting() bool {
+	select {
+	case <-c.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// Listen reads messages from the IRC server until the connection fails,
+// returning the read error.
+func (c *Client) Listen() error {
+	c.connMu.Lock()
+	reader := c.reader
+	c.connMu.Unlock()
+
 	for {
-		line, err := c.reader.ReadString('\n')
+		msg, err := c.readMessage(reader)
 		if err != nil {
 			log.Printf("Error reading from IRC: %v", err)
 			c.Close() // Use the public Close method
-			return
+			return err
+		}
+		c.dispatch(msg)
+	}
+}
+
+// readMessage reads the next well-formed message, skipping malformed lines.
+func (c *Client) readMessage(reader *bufio.Reader) (*Message, error) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
 		}
 
-		line = strings.TrimSpace(line)
+		line = strings.TrimRight(line, "\r\n")
 		log.Printf("<-- %s", line)
 
-		// Handle PING requests
-		if strings.HasPrefix(line, "PING :") {
-			pongMsg := strings.TrimPrefix(line, "PING :")
-			c.Send("PONG :%s", pongMsg)
+		msg, err := ParseMessage(line)
+		if err != nil {
+			log.Printf("Ignoring malformed IRC line %q: %v", line, err)
 			continue
 		}
+		return msg, nil
+	}
+}
 
-		// Parse PRIVMSG
-		if strings.Contains(line, " PRIVMSG ") {
-			parts := strings.SplitN(line, " PRIVMSG ", 2)
-			if len(parts) != 2 {
-				continue
-			}
-
-			prefix := parts[0]
-			messagePart := parts[1]
-
-			user := ""
-			if strings.HasPrefix(prefix, ":") {
-				user = strings.SplitN(prefix[1:], "!", 2)[0]
-			}
-
-			msgParts := strings.SplitN(messagePart, " :", 2)
-			if len(msgParts) != 2 {
-				continue
-			}
-
-			target := msgParts[0]
-			message := msgParts[1]
-
-			target = strings.TrimSpace(target)
-			message = strings.TrimSpace(message)
+// dispatch handles protocol-level messages and passes everything to the Handler.
+func (c *Client) dispatch(msg *Message) {
+	c.trackUsers(msg)
 
-			if c.Handler != nil {
-				c.Handler(target, user, message)
+	switch msg.Command {
+	case "PING":
+		c.Send("PONG :%s", msg.Trailing())
+		return
+	case RPL_WELCOME:
+		c.onWelcome(msg.Param(0))
+	case "NICK", "QUIT":
+		c.trackNick(msg)
+	case "CAP":
+		if strings.EqualFold(msg.Param(1), "DEL") {
+			c.stateMu.Lock()
+			for _, name := range strings.Fields(msg.Trailing()) {
+				delete(c.caps, name)
 			}
+			c.stateMu.Unlock()
+		}
+	case "JOIN":
+		if c.IsMe(msg.Nick()) && msg.Source != nil {
+			c.setUserHost(msg.Source.User, msg.Source.Host)
+		}
+	case RPL_HOSTHIDDEN:
+		c.setUserHost("", msg.Param(1))
+	case "CHGHOST":
+		if c.IsMe(msg.Nick()) {
+			c.setUserHost(msg.Param(0), msg.Param(1))
+		}
+	case "PART":
+		if c.IsMe(msg.Nick()) {
+			c.forgetChannel(msg.Param(0))
 		}
+	case "KICK":
+		if c.IsMe(msg.Param(1)) {
+			c.forgetChannel(msg.Param(0))
+		}
+	}
+
+	if c.Handler != nil {
+		c.Handler(msg)
 	}
 }
@@ -3,6 +3,7 @@ package irc
 import (
 	"bytes"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -15,39 +16,72 @@ import (
 )
 
 // MockConn implements net.Conn for testing purposes.
+// Reads block until data is injected or the connection is closed, like a real socket.
 type MockConn struct {
 	readBuffer  bytes.Buffer
 	writeBuffer bytes.Buffer
+	mu          sync.Mutex // Guards both buffers; the client reads and writes from its own goroutines
+	readReady   *sync.Cond
+	isClosed    bool
+	respond     func(line string) string // Scripted server: reply to each line the client writes
 	closeOnce   sync.Once
 	closed      chan struct{}
 }
 
 func NewMockConn() *MockConn {
-	return &MockConn{
+	m := &MockConn{
 		closed: make(chan struct{}),
 	}
+	m.readReady = sync.NewCond(&m.mu)
+	return m
 }
 
 func (m *MockConn) Read(b []byte) (n int, err error) {
-	select {
-	case <-m.closed:
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for m.readBuffer.Len() == 0 && !m.isClosed {
+		m.readReady.Wait()
+	}
+	if m.isClosed {
 		return 0, io.EOF
-	default:
-		return m.readBuffer.Read(b)
 	}
+	return m.readBuffer.Read(b)
 }
 
 func (m *MockConn) Write(b []byte) (n int, err error) {
-	select {
-	case <-m.closed:
+	m.mu.Lock()
+	if m.isClosed {
+		m.mu.Unlock()
 		return 0, io.EOF
-	default:
-		return m.writeBuffer.Write(b)
 	}
+	n, err = m.writeBuffer.Write(b)
+	respond := m.respond
+	m.mu.Unlock()
+
+	if respond != nil {
+		for _, line := range strings.Split(strings.TrimRight(string(b), "\r\n"), "\r\n") {
+			if reply := respond(line); reply != "" {
+				m.InjectReadData(reply)
+			}
+		}
+	}
+	return n, err
+}
+
+// Script makes the connection answer each line the client writes with the
+// reply returned by respond, letting tests play the server side of a conversation.
+func (m *MockConn) Script(respond func(line string) string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.respond = respond
 }
 
 func (m *MockConn) Close() error {
 	m.closeOnce.Do(func() {
+		m.mu.Lock()
+		m.isClosed = true
+		m.readReady.Broadcast()
+		m.mu.Unlock()
 		close(m.closed)
 	})
 	return nil
@@ -75,16 +109,23 @@ func (m *MockConn) SetWriteDeadline(t time.Time) error {
 
 // InjectReadData injects data into the read buffer to simulate incoming messages.
 func (m *MockConn) InjectReadData(data string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.readBuffer.WriteString(data)
+	m.readReady.Broadcast()
 }
 
 // GetWrittenData retrieves data written to the connection.
 func (m *MockConn) GetWrittenData() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return m.writeBuffer.String()
 }
 
 // ClearWrittenData clears the write buffer.
 func (m *MockConn) ClearWrittenData() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.writeBuffer.Reset()
 }
 
@@ -148,10 +189,11 @@ func TestConnect(t *testing.T) {
 			t.Fatalf("Connect(false) failed: %v", err)
 		}
 
+		client.Flush(time.Second)
 		written := mockConn.GetWrittenData()
-		expected := fmt.Sprintf("NICK %s\r\nUSER %s 0 * :%s\r\n", cfg.BotName, cfg.BotName, cfg.BotName)
+		expected := fmt.Sprintf("CAP LS 302\r\nNICK %s\r\nUSER %s 0 * :%s\r\n", cfg.BotName, cfg.BotName, cfg.BotName)
 		if written != expected {
-			t.Errorf("Expected NICK/USER commands:\n%q\nGot:\n%q", expected, written)
+			t.Errorf("Expected CAP/NICK/USER commands:\n%q\nGot:\n%q", expected, written)
 		}
 		mockConn.ClearWrittenData()
 		client.Close()
@@ -173,10 +215,11 @@ func TestConnect(t *testing.T) {
 			t.Fatalf("Connect(true) failed: %v", err)
 		}
 
+		client.Flush(time.Second)
 		written := mockConn.GetWrittenData()
-		expected := fmt.Sprintf("NICK %s\r\nUSER %s 0 * :%s\r\n", cfg.BotName, cfg.BotName, cfg.BotName)
+		expected := fmt.Sprintf("CAP LS 302\r\nNICK %s\r\nUSER %s 0 * :%s\r\n", cfg.BotName, cfg.BotName, cfg.BotName)
 		if written != expected {
-			t.Errorf("Expected NICK/USER commands:\n%q\nGot:\n%q", expected, written)
+			t.Errorf("Expected CAP/NICK/USER commands:\n%q\nGot:\n%q", expected, written)
 		}
 		mockConn.ClearWrittenData()
 		client.Close()
@@ -215,11 +258,13 @@ func TestSend(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Connect failed: %v", err)
 	}
+	client.Flush(time.Second)
 	mockConn.ClearWrittenData() // Clear NICK/USER commands
 
 	testMsg := "PRIVMSG #channel :Hello"
 	client.Send("%s", testMsg)
 
+	client.Flush(time.Second)
 	written := mockConn.GetWrittenData()
 	expected := testMsg + "\r\n"
 	if written != expected {
@@ -243,10 +288,16 @@ func TestJoinChannel(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Connect failed: %v", err)
 	}
+	mockConn.InjectReadData(":irc.example.com 001 TestBot :Welcome\r\n")
+	if err := client.Register(); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	client.Flush(time.Second)
 	mockConn.ClearWrittenData() // Clear NICK/USER commands
 
 	client.JoinChannel("#test")
 
+	client.Flush(time.Second)
 	written := mockConn.GetWrittenData()
 	expected := "JOIN #test\r\n"
 	if written != expected {
@@ -270,10 +321,16 @@ func TestPrivmsg(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Connect failed: %v", err)
 	}
+	mockConn.InjectReadData(":irc.example.com 001 TestBot :Welcome\r\n")
+	if err := client.Register(); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	client.Flush(time.Second)
 	mockConn.ClearWrittenData() // Clear NICK/USER commands
 
 	client.Privmsg("#channel", "Hello, world!")
 
+	client.Flush(time.Second)
 	written := mockConn.GetWrittenData()
 	expected := "PRIVMSG #channel :Hello, world!\r\n"
 	if written != expected {
@@ -328,6 +385,7 @@ func TestListen(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Connect failed: %v", err)
 		}
+		client.Flush(time.Second)
 		mockConn.ClearWrittenData() // Clear NICK/USER commands
 
 		mockConn.InjectReadData("PING :irc.example.com\r\n")
@@ -335,6 +393,7 @@ func TestListen(t *testing.T) {
 
 		// Wait for PONG to be written
 		time.Sleep(50 * time.Millisecond) // Give goroutine time to process
+		client.Flush(time.Second)
 		written := mockConn.GetWrittenData()
 		expected := "PONG :irc.example.com\r\n"
 		if written != expected {
@@ -361,10 +420,15 @@ func TestListen(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Connect failed: %v", err)
 		}
+		client.Flush(time.Second)
 		mockConn.ClearWrittenData() // Clear NICK/USER commands
 
 		handlerCalled := make(chan struct{})
-		client.Handler = func(target, user, message string) {
+		client.Handler = func(msg *Message) {
+			if msg.Command != "PRIVMSG" {
+				return
+			}
+			target, user, message := msg.Param(0), msg.Nick(), msg.Trailing()
 			if target != "#channel" {
 				t.Errorf("Expected target #channel, got %q", target)
 			}
@@ -406,6 +470,7 @@ func TestListen(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Connect failed: %v", err)
 		}
+		client.Flush(time.Second)
 		mockConn.ClearWrittenData() // Clear NICK/USER commands
 
 		// Simulate read error by closing the connection immediately
@@ -425,3 +490,200 @@ func TestListen(t *testing.T) {
 		}
 	})
 }
+
+func TestRunReconnects(t *testing.T) {
+	cfg := &config.Config{
+		BotName:   "TestBot",
+		IRCServer: "irc.example.com:6667",
+	}
+	client := NewClient(cfg)
+	client.ReconnectMin = time.Millisecond
+	client.ReconnectMax = 5 * time.Millisecond
+
+	// The first connection registers and then drops; the second one stays up.
+	first := NewMockConn()
+	first.InjectReadData(":irc.example.com 001 TestBot :Welcome\r\n")
+	second := NewMockConn()
+	second.InjectReadData(":irc.example.com 001 TestBot :Welcome\r\nPING :keepalive\r\n")
+	conns := make(chan *MockConn, 2)
+	conns <- first
+	conns <- second
+	mockDial = func(network, address string) (net.Conn, error) {
+		select {
+		case c := <-conns:
+			return c, nil
+		default:
+			return nil, fmt.Errorf("no more connections")
+		}
+	}
+	defer func() { mockDial = nil }()
+
+	var mu sync.Mutex
+	disconnects := 0
+	connected := make(chan struct{}, 2)
+	client.OnDisconnect = func(error) {
+		mu.Lock()
+		disconnects++
+		mu.Unlock()
+	}
+	client.OnConnect = func() { connected <- struct{}{} }
+	client.JoinChannel("#trivia") // Not connected yet; remembered for Run
+
+	runDone := make(chan error)
+	go func() { runDone <- client.Run(false) }()
+
+	<-connected
+	client.Flush(time.Second)
+	if !strings.Contains(first.GetWrittenData(), "JOIN #trivia\r\n") {
+		t.Errorf("First connection did not join; wrote %q", first.GetWrittenData())
+	}
+	first.Close() // Simulate the network dropping
+
+	deadline := time.After(time.Second)
+	for !strings.Contains(second.GetWrittenData(), "JOIN #trivia\r\n") {
+		select {
+		case <-deadline:
+			t.Fatalf("Second connection never rejoined; wrote %q", second.GetWrittenData())
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	<-connected
+
+	mu.Lock()
+	if disconnects < 1 {
+		t.Errorf("Expected OnDisconnect to be called, got %d calls", disconnects)
+	}
+	mu.Unlock()
+
+	client.Quit("bye")
+	select {
+	case err := <-runDone:
+		if err != nil {
+			t.Errorf("Run returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Quit")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := NewBackoff(100*time.Millisecond, time.Second)
+	limits := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, limit := range limits {
+		limit *= time.Millisecond
+		d := b.Next()
+		if d < limit/2 || d > limit {
+			t.Errorf("attempt %d: delay %s outside [%s, %s]", i, d, limit/2, limit)
+		}
+	}
+
+	b.Reset()
+	if d := b.Next(); d > 100*time.Millisecond {
+		t.Errorf("delay after Reset = %s, want <= 100ms", d)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	cfg := &config.Config{
+		BotName:   "TestBot",
+		IRCServer: "irc.example.com:6667",
+	}
+
+	t.Run("QueuesUntilWelcome", func(t *testing.T) {
+		client := NewClient(cfg)
+		mockConn := NewMockConn()
+		mockDial = func(network, address string) (net.Conn, error) {
+			return mockConn, nil
+		}
+		defer func() { mockDial = nil }()
+
+		client.FloodBurst = 10 // Not testing flood control here
+		if err := client.Connect(false); err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		client.Flush(time.Second)
+		mockConn.ClearWrittenData()
+
+		client.JoinChannel("#trivia")
+		client.Privmsg("#trivia", "early bird")
+		client.Flush(time.Second)
+		if written := mockConn.GetWrittenData(); written != "" {
+			t.Fatalf("Expected nothing sent before registration, got %q", written)
+		}
+
+		mockConn.InjectReadData("PING :123\r\n:irc.example.com 001 TestBot :Welcome\r\n")
+		if err := client.Register(); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		if !client.Registered() {
+			t.Error("Expected client to be registered")
+		}
+
+		expected := "PONG :123\r\nJOIN #trivia\r\nPRIVMSG #trivia :early bird\r\n"
+		client.Flush(time.Second)
+		if written := mockConn.GetWrittenData(); written != expected {
+			t.Errorf("Expected %q, got %q", expected, written)
+		}
+		client.Close()
+	})
+
+	failures := []struct {
+		numeric string
+		line    string
+		name    string
+	}{
+		{"432", ":irc.example.com 432 * Bad*Nick :Erroneous nickname\r\n", "ERR_ERRONEUSNICKNAME"},
+		// Every candidate nick (BOT_NAME plus the underscore variants) is taken
+		{"433", strings.Repeat(":irc.example.com 433 * TestBot :Nickname is already in use\r\n", 1+maxUnderscoreNicks), "ERR_NICKNAMEINUSE"},
+		{"464", ":irc.example.com 464 * :Password incorrect\r\n", "ERR_PASSWDMISMATCH"},
+		{"465", ":irc.example.com 465 * :You are banned\r\n", "ERR_YOUREBANNEDCREEP"},
+	}
+	for _, f := range failures {
+		t.Run("Fails"+f.numeric, func(t *testing.T) {
+			client := NewClient(cfg)
+			mockConn := NewMockConn()
+			mockDial = func(network, address string) (net.Conn, error) {
+				return mockConn, nil
+			}
+			defer func() { mockDial = nil }()
+
+			if err := client.Connect(false); err != nil {
+				t.Fatalf("Connect failed: %v", err)
+			}
+			mockConn.InjectReadData(f.line)
+
+			err := client.Register()
+			var regErr *RegistrationError
+			if !errors.As(err, &regErr) {
+				t.Fatalf("Expected *RegistrationError, got %v", err)
+			}
+			if regErr.Numeric != f.numeric {
+				t.Errorf("Expected numeric %s, got %s", f.numeric, regErr.Numeric)
+			}
+			if !strings.Contains(err.Error(), f.numeric) || !strings.Contains(err.Error(), f.name) {
+				t.Errorf("Error %q should name numeric %s (%s)", err, f.numeric, f.name)
+			}
+			if client.Registered() {
+				t.Error("Client should not be registered")
+			}
+			client.Close()
+		})
+	}
+
+	t.Run("ConnectionClosed", func(t *testing.T) {
+		client := NewClient(cfg)
+		mockConn := NewMockConn()
+		mockDial = func(network, address string) (net.Conn, error) {
+			return mockConn, nil
+		}
+		defer func() { mockDial = nil }()
+
+		if err := client.Connect(false); err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		mockConn.Close()
+		if err := client.Register(); err == nil {
+			t.Fatal("Expected error when connection closes during registration")
+		}
+	})
+}
@@ -0,0 +1,238 @@
+package irc
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Prefix is the source of an IRC message: either a server name or a
+// nick!user@host mask.
+type Prefix struct {
+	Name string // Nickname or server name
+	User string
+	Host string
+}
+
+// ParsePrefix parses a message source such as "nick!user@host".
+func ParsePrefix(s string) *Prefix {
+	p := &Prefix{}
+	if i := strings.IndexByte(s, '@'); i >= 0 {
+		p.Host = s[i+1:]
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '!'); i >= 0 {
+		p.User = s[i+1:]
+		s = s[:i]
+	}
+	p.Name = s
+	return p
+}
+
+// String returns the prefix in nick!user@host form, omitting empty parts.
+func (p *Prefix) String() string {
+	s := p.Name
+	if p.User != "" {
+		s += "!" + p.User
+	}
+	if p.Host != "" {
+		s += "@" + p.Host
+	}
+	return s
+}
+
+// Message is a single parsed IRC protocol line, as described by RFC 1459/2812
+// and extended by the IRCv3 message-tags specification.
+type Message struct {
+	Tags    map[string]string // IRCv3 message tags; valueless tags map to ""
+	Source  *Prefix           // Nil when the message has no source
+	Command string            // Upper-cased command or three-digit numeric
+	Params  []string          // Middle params followed by the trailing param, if any
+}
+
+var (
+	errEmptyMessage = errors.New("empty message")
+	errNoCommand    = errors.New("message has no command")
+)
+
+// ParseMessage parses a raw IRC line. Any trailing CR/LF is ignored.
+func ParseMessage(line string) (*Message, error) {
+	line = strings.TrimRight(line, "\r\n")
+	line = strings.TrimLeft(line, " ")
+	if line == "" {
+		return nil, errEmptyMessage
+	}
+
+	m := &Message{}
+
+	if line[0] == '@' {
+		var rawTags string
+		rawTags, line = cutWord(line[1:])
+		m.Tags = parseTags(rawTags)
+	}
+
+	if line != "" && line[0] == ':' {
+		var source string
+		source, line = cutWord(line[1:])
+		m.Source = ParsePrefix(source)
+	}
+
+	var command string
+	command, line = cutWord(line)
+	if command == "" {
+		return nil, errNoCommand
+	}
+	m.Command = strings.ToUpper(command)
+
+	for line != "" {
+		if line[0] == ':' {
+			m.Params = append(m.Params, line[1:])
+			break
+		}
+		var param string
+		param, line = cutWord(line)
+		m.Params = append(m.Params, param)
+	}
+
+	return m, nil
+}
+
+// cutWord splits s at the first space and strips any extra spaces that follow it.
+func cutWord(s string) (string, string) {
+	word, rest, _ := strings.Cut(s, " ")
+	return word, strings.TrimLeft(rest, " ")
+}
+
+// parseTags parses the tag section of a message (without the leading '@').
+func parseTags(raw string) map[string]string {
+	tags := make(map[string]string)
+	for _, tag := range strings.Split(raw, ";") {
+		if tag == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(tag, "=")
+		tags[key] = unescapeTagValue(value)
+	}
+	return tags
+}
+
+// unescapeTagValue reverses the IRCv3 tag value escaping rules.
+func unescapeTagValue(v string) string {
+	if !strings.Contains(v, `\`) {
+		return v
+	}
+	var b strings.Builder
+	b.Grow(len(v))
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' {
+			b.WriteByte(v[i])
+			continue
+		}
+		i++
+		if i >= len(v) {
+			break // A trailing lone backslash is dropped
+		}
+		switch v[i] {
+		case ':':
+			b.WriteByte(';')
+		case 's':
+			b.WriteByte(' ')
+		case 'r':
+			b.WriteByte('\r')
+		case 'n':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(v[i]) // Covers "\\" and any invalid escape
+		}
+	}
+	return b.String()
+}
+
+// escapeTagValue applies the IRCv3 tag value escaping rules.
+func escapeTagValue(v string) string {
+	return tagEscaper.Replace(v)
+}
+
+var tagEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\:`,
+	" ", `\s`,
+	"\r", `\r`,
+	"\n", `\n`,
+)
+
+// Param returns the i-th parameter, or "" if it does not exist.
+func (m *Message) Param(i int) string {
+	if i < 0 || i >= len(m.Params) {
+		return ""
+	}
+	return m.Params[i]
+}
+
+// Trailing returns the last parameter, or "" if there are none.
+func (m *Message) Trailing() string {
+	if len(m.Params) == 0 {
+		return ""
+	}
+	return m.Params[len(m.Params)-1]
+}
+
+// Nick returns the nickname (or server name) of the message source.
+func (m *Message) Nick() string {
+	if m.Source == nil {
+		return ""
+	}
+	return m.Source.Name
+}
+
+// Tag returns the value of a message tag and whether it was present.
+func (m *Message) Tag(key string) (string, bool) {
+	v, ok := m.Tags[key]
+	return v, ok
+}
+
+// String serializes the message back into a protocol line without CRLF.
+// Tags are written in sorted order so the output is deterministic.
+func (m *Message) String() string {
+	var b strings.Builder
+
+	if len(m.Tags) > 0 {
+		keys := make([]string, 0, len(m.Tags))
+		for k := range m.Tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		b.WriteByte('@')
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(';')
+			}
+			b.WriteString(k)
+			if v := m.Tags[k]; v != "" {
+				b.WriteByte('=')
+				b.WriteString(escapeTagValue(v))
+			}
+		}
+		b.WriteByte(' ')
+	}
+
+	if m.Source != nil {
+		b.WriteByte(':')
+		b.WriteString(m.Source.String())
+		b.WriteByte(' ')
+	}
+
+	b.WriteString(m.Command)
+
+	for i, p := range m.Params {
+		b.WriteByte(' ')
+		last := i == len(m.Params)-1
+		if last && (p == "" || p[0] == ':' || strings.ContainsRune(p, ' ')) {
+			b.WriteByte(':')
+		}
+		b.WriteString(p)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,176 @@
+package irc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want *Message
+	}{
+		{
+			name: "CommandOnly",
+			line: "PING\r\n",
+			want: &Message{Command: "PING"},
+		},
+		{
+			name: "PingWithTrailing",
+			line: "PING :irc.example.com",
+			want: &Message{Command: "PING", Params: []string{"irc.example.com"}},
+		},
+		{
+			name: "FullSource",
+			line: ":nick!~user@host.example PRIVMSG #chan :Hello there",
+			want: &Message{
+				Source:  &Prefix{Name: "nick", User: "~user", Host: "host.example"},
+				Command: "PRIVMSG",
+				Params:  []string{"#chan", "Hello there"},
+			},
+		},
+		{
+			name: "ServerSource",
+			line: ":irc.example.com 001 TrebekBot :Welcome to the network",
+			want: &Message{
+				Source:  &Prefix{Name: "irc.example.com"},
+				Command: "001",
+				Params:  []string{"TrebekBot", "Welcome to the network"},
+			},
+		},
+		{
+			name: "TrailingWithColonsAndCommand",
+			line: ":a!b@c PRIVMSG #chan :: PRIVMSG #other :not a command",
+			want: &Message{
+				Source:  &Prefix{Name: "a", User: "b", Host: "c"},
+				Command: "PRIVMSG",
+				Params:  []string{"#chan", ": PRIVMSG #other :not a command"},
+			},
+		},
+		{
+			name: "EmptyTrailing",
+			line: "TOPIC #chan :",
+			want: &Message{Command: "TOPIC", Params: []string{"#chan", ""}},
+		},
+		{
+			name: "ExtraSpaces",
+			line: ":src   JOIN    #chan",
+			want: &Message{Source: &Prefix{Name: "src"}, Command: "JOIN", Params: []string{"#chan"}},
+		},
+		{
+			name: "LowercaseCommand",
+			line: "privmsg #chan hi",
+			want: &Message{Command: "PRIVMSG", Params: []string{"#chan", "hi"}},
+		},
+		{
+			name: "Tags",
+			line: `@account=bob;time=2026-01-01T00:00:00.000Z;+draft/reply;msg=a\sb\:c\\d\r\n :bob!b@h PRIVMSG #c :hi`,
+			want: &Message{
+				Tags: map[string]string{
+					"account":      "bob",
+					"time":         "2026-01-01T00:00:00.000Z",
+					"+draft/reply": "",
+					"msg":          "a b;c\\d\r\n",
+				},
+				Source:  &Prefix{Name: "bob", User: "b", Host: "h"},
+				Command: "PRIVMSG",
+				Params:  []string{"#c", "hi"},
+			},
+		},
+		{
+			name: "TagTrailingBackslashAndInvalidEscape",
+			line: `@a=x\y;b=z\ CMD`,
+			want: &Message{Tags: map[string]string{"a": "xy", "b": "z"}, Command: "CMD"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMessage(tt.line)
+			if err != nil {
+				t.Fatalf("ParseMessage(%q) returned error: %v", tt.line, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMessage(%q)\n got: %#v\nwant: %#v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMessageErrors(t *testing.T) {
+	for _, line := range []string{"", "\r\n", "   ", ":source.only", "@tag=1 "} {
+		if _, err := ParseMessage(line); err == nil {
+			t.Errorf("ParseMessage(%q) expected error, got nil", line)
+		}
+	}
+}
+
+func TestMessageString(t *testing.T) {
+	tests := []struct {
+		msg  *Message
+		want string
+	}{
+		{&Message{Command: "PONG", Params: []string{"irc.example.com"}}, "PONG irc.example.com"},
+		{&Message{Command: "PRIVMSG", Params: []string{"#c", "hello world"}}, "PRIVMSG #c :hello world"},
+		{&Message{Command: "PRIVMSG", Params: []string{"#c", ":)"}}, "PRIVMSG #c ::)"},
+		{&Message{Command: "TOPIC", Params: []string{"#c", ""}}, "TOPIC #c :"},
+		{
+			&Message{
+				Tags:    map[string]string{"b": "x y", "a": ""},
+				Source:  &Prefix{Name: "n", User: "u", Host: "h"},
+				Command: "NOTICE",
+				Params:  []string{"t", "m"},
+			},
+			`@a;b=x\sy :n!u@h NOTICE t m`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.msg.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	lines := []string{
+		`@account=bob;msg=a\sb\:c :bob!b@h PRIVMSG #c :hi there`,
+		":irc.example.com 353 Bot = #chan :@op +voice user",
+		"CAP * LS :sasl message-tags",
+	}
+	for _, line := range lines {
+		msg, err := ParseMessage(line)
+		if err != nil {
+			t.Fatalf("ParseMessage(%q) returned error: %v", line, err)
+		}
+		if got := msg.String(); got != line {
+			t.Errorf("round trip mismatch:\n got: %q\nwant: %q", got, line)
+		}
+	}
+}
+
+func TestMessageAccessors(t *testing.T) {
+	msg, err := ParseMessage("@account=bob :bob!b@h PRIVMSG #c :hi")
+	if err != nil {
+		t.Fatalf("ParseMessage returned error: %v", err)
+	}
+	if msg.Nick() != "bob" {
+		t.Errorf("Nick() = %q, want bob", msg.Nick())
+	}
+	if msg.Param(0) != "#c" || msg.Param(5) != "" {
+		t.Errorf("Param() returned unexpected values: %q, %q", msg.Param(0), msg.Param(5))
+	}
+	if msg.Trailing() != "hi" {
+		t.Errorf("Trailing() = %q, want hi", msg.Trailing())
+	}
+	if v, ok := msg.Tag("account"); !ok || v != "bob" {
+		t.Errorf("Tag(account) = %q, %t", v, ok)
+	}
+	if _, ok := msg.Tag("missing"); ok {
+		t.Error("Tag(missing) should not be present")
+	}
+	if (&Message{Command: "PING"}).Nick() != "" {
+		t.Error("Nick() should be empty without a source")
+	}
+}
@@ -0,0 +1,126 @@
+package irc
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	defaultNickRegainInterval = 60 * time.Second
+	maxUnderscoreNicks        = 3 // How many "_" suffixes to try after the configured alternates
+)
+
+// Nick returns the nickname currently in use, which may differ from the
+// configured BOT_NAME if it was taken during registration.
+func (c *Client) Nick() string {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	return c.nick
+}
+
+// IsMe reports whether nick is the bot's current nickname.
+func (c *Client) IsMe(nick string) bool {
+	return strings.EqualFold(nick, c.Nick())
+}
+
+// setNick records the nickname the server says we are using. Config.BotName
+// keeps the configured nick; Nick reports the current one.
+func (c *Client) setNick(nick string) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	c.nick = nick
+}
+
+// nickCandidates lists the nicks to try in order: the primary nick, the
+// configured alternates, then the primary nick with underscores appended.
+func (c *Client) nickCandidates() []string {
+	candidates := []string{c.primaryNick}
+	for _, alt := range strings.Split(c.Config.AltNicks, ",") {
+		if alt = strings.TrimSpace(alt); alt != "" {
+			candidates = append(candidates, alt)
+		}
+	}
+	for i := 1; i <= maxUnderscoreNicks; i++ {
+		candidates = append(candidates, c.primaryNick+strings.Repeat("_", i))
+	}
+	return candidates
+}
+
+// tryNextNick sends NICK with the next untried candidate during registration.
+// It returns false once every candidate has been rejected.
+func (c *Client) tryNextNick() bool {
+	candidates := c.nickCandidates()
+	c.stateMu.Lock()
+	c.nickAttempt++
+	attempt := c.nickAttempt
+	c.stateMu.Unlock()
+
+	if attempt >= len(candidates) {
+		return false
+	}
+	nick := candidates[attempt]
+	log.Printf("Nickname %s is unavailable, trying %s", candidates[attempt-1], nick)
+	c.setNick(nick)
+	c.Send("NICK %s", nick)
+	return true
+}
+
+// regainNick asks for the primary nick back if we are using an alternate.
+func (c *Client) regainNick() {
+	if c.IsMe(c.primaryNick) || !c.Registered() {
+		return
+	}
+	c.Send("NICK %s", c.primaryNick)
+}
+
+// ghostPrimaryNick asks NickServ to disconnect whoever holds the primary nick.
+// It only does anything when there are credentials NickServ can check.
+func (c *Client) ghostPrimaryNick() {
+	if c.IsMe(c.primaryNick) {
+		return
+	}
+	switch {
+	case c.Config.NickServPassword != "":
+		c.Send("PRIVMSG NickServ :GHOST %s %s", c.primaryNick, c.Config.NickServPassword)
+	case c.HasCap("sasl"):
+		c.Send("PRIVMSG NickServ :GHOST %s", c.primaryNick) // Already identified via SASL
+	}
+}
+
+// watchNick periodically tries to regain the primary nick until done is closed.
+func (c *Client) watchNick(done <-chan struct{}) {
+	if c.NickRegainInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.NickRegainInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			c.regainNick()
+		}
+	}
+}
+
+// trackNick follows nick changes after registration: our own renames, and the
+// primary nick becoming free when its holder quits or changes nick.
+func (c *Client) trackNick(msg *Message) {
+	switch msg.Command {
+	case "NICK":
+		if c.IsMe(msg.Nick()) {
+			c.setNick(msg.Param(0))
+			log.Printf("Now known as %s", msg.Param(0))
+			return
+		}
+		if strings.EqualFold(msg.Nick(), c.primaryNick) {
+			c.regainNick()
+		}
+	case "QUIT":
+		if strings.EqualFold(msg.Nick(), c.primaryNick) {
+			c.regainNick()
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package irc
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"trebek/internal/config"
+)
+
+// nickTakenServer scripts a server where the given nicks are already in use.
+func nickTakenServer(taken ...string) func(string) string {
+	return func(line string) string {
+		nick, ok := strings.CutPrefix(line, "NICK ")
+		if !ok {
+			return ""
+		}
+		for _, t := range taken {
+			if strings.EqualFold(nick, t) {
+				return ":irc.example.com 433 * " + nick + " :Nickname is already in use\r\n"
+			}
+		}
+		return ":irc.example.com 001 " + nick + " :Welcome\r\n"
+	}
+}
+
+func TestAlternateNicks(t *testing.T) {
+	t.Run("ConfiguredAlternates", func(t *testing.T) {
+		cfg := &config.Config{BotName: "TestBot", AltNicks: "AltBot, OtherBot", IRCServer: "irc.example.com:6667"}
+		client, mockConn := newScriptedClient(t, cfg, nickTakenServer("TestBot", "AltBot"))
+		defer client.Close()
+
+		if err := client.Register(); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		if client.Nick() != "OtherBot" {
+			t.Errorf("Expected nick OtherBot, got %q", client.Nick())
+		}
+		if cfg.BotName != "TestBot" {
+			t.Errorf("Config.BotName should keep the configured nick, got %q", cfg.BotName)
+		}
+		client.Flush(time.Second)
+		if !strings.Contains(mockConn.GetWrittenData(), "NICK AltBot\r\nNICK OtherBot\r\n") {
+			t.Errorf("Expected alternates to be tried in order, got %q", mockConn.GetWrittenData())
+		}
+	})
+
+	t.Run("Underscores", func(t *testing.T) {
+		cfg := &config.Config{BotName: "TestBot", IRCServer: "irc.example.com:6667"}
+		client, _ := newScriptedClient(t, cfg, nickTakenServer("TestBot", "TestBot_"))
+		defer client.Close()
+
+		if err := client.Register(); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		if client.Nick() != "TestBot__" {
+			t.Errorf("Expected nick TestBot__, got %q", client.Nick())
+		}
+	})
+
+	t.Run("ReconnectStartsWithPrimary", func(t *testing.T) {
+		cfg := &config.Config{BotName: "TestBot", IRCServer: "irc.example.com:6667"}
+		client, _ := newScriptedClient(t, cfg, nickTakenServer("TestBot"))
+		if err := client.Register(); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		client.Close()
+
+		mockConn := NewMockConn()
+		mockDial = func(string, string) (net.Conn, error) { return mockConn, nil }
+		if err := client.Connect(false); err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		defer client.Close()
+		client.Flush(time.Second)
+		if !strings.Contains(mockConn.GetWrittenData(), "NICK TestBot\r\n") {
+			t.Errorf("Reconnect should ask for the primary nick again, got %q", mockConn.GetWrittenData())
+		}
+	})
+}
+
+func TestRegainNick(t *testing.T) {
+	newRegistered := func(t *testing.T, cfg *config.Config) (*Client, *MockConn) {
+		t.Helper()
+		client, mockConn := newScriptedClient(t, cfg, nickTakenServer("TestBot"))
+		if err := client.Register(); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		mockConn.Script(nil)
+		client.Flush(time.Second)
+		mockConn.ClearWrittenData()
+		return client, mockConn
+	}
+
+	t.Run("OnQuit", func(t *testing.T) {
+		cfg := &config.Config{BotName: "TestBot", IRCServer: "irc.example.com:6667"}
+		client, mockConn := newRegistered(t, cfg)
+		defer client.Close()
+
+		mockConn.InjectReadData(":TestBot!old@host QUIT :Ping timeout\r\n:TestBot_!bot@host NICK :TestBot\r\n")
+		go client.Listen()
+
+		deadline := time.After(time.Second)
+		for client.Nick() != "TestBot" {
+			select {
+			case <-deadline:
+				t.Fatalf("Nick was not regained; wrote %q", mockConn.GetWrittenData())
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+		client.Flush(time.Second)
+		if !strings.Contains(mockConn.GetWrittenData(), "NICK TestBot\r\n") {
+			t.Errorf("Expected NICK TestBot after the holder quit, got %q", mockConn.GetWrittenData())
+		}
+	})
+
+	t.Run("Periodic", func(t *testing.T) {
+		cfg := &config.Config{BotName: "TestBot", IRCServer: "irc.example.com:6667"}
+		client := NewClient(cfg)
+		client.NickRegainInterval = 10 * time.Millisecond
+		mockConn := NewMockConn()
+		mockConn.Script(nickTakenServer("TestBot"))
+		mockDial = func(string, string) (net.Conn, error) { return mockConn, nil }
+		defer func() { mockDial = nil }()
+		if err := client.Connect(false); err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		if err := client.Register(); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		client.Flush(time.Second)
+		mockConn.ClearWrittenData()
+
+		time.Sleep(50 * time.Millisecond)
+		client.Flush(time.Second)
+		client.Close()
+		if !strings.Contains(mockConn.GetWrittenData(), "NICK TestBot\r\n") {
+			t.Errorf("Expected periodic NICK TestBot, got %q", mockConn.GetWrittenData())
+		}
+	})
+
+	t.Run("NickServGhost", func(t *testing.T) {
+		cfg := &config.Config{BotName: "TestBot", NickServPassword: "s3cret", IRCServer: "irc.example.com:6667"}
+		client, mockConn := newScriptedClient(t, cfg, nickTakenServer("TestBot"))
+		defer client.Close()
+		if err := client.Register(); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		client.Flush(time.Second)
+		if !strings.Contains(mockConn.GetWrittenData(), "PRIVMSG NickServ :GHOST TestBot s3cret\r\n") {
+			t.Errorf("Expected NickServ GHOST, got %q", mockConn.GetWrittenData())
+		}
+	})
+
+	t.Run("IgnoresOthers", func(t *testing.T) {
+		cfg := &config.Config{BotName: "TestBot", IRCServer: "irc.example.com:6667"}
+		client, mockConn := newRegistered(t, cfg)
+		defer client.Close()
+
+		client.dispatch(mustParse(t, ":someone!a@b QUIT :bye"))
+		client.dispatch(mustParse(t, ":someone!a@b NICK :someone_else"))
+		client.Flush(time.Second)
+		if written := mockConn.GetWrittenData(); written != "" {
+			t.Errorf("Unrelated QUIT/NICK should not trigger anything, got %q", written)
+		}
+		if client.Nick() != "TestBot_" {
+			t.Errorf("Nick should be unchanged, got %q", client.Nick())
+		}
+	})
+}
+
+func mustParse(t *testing.T, line string) *Message {
+	t.Helper()
+	msg, err := ParseMessage(line)
+	if err != nil {
+		t.Fatalf("ParseMessage(%q) failed: %v", line, err)
+	}
+	return msg
+}
@@ -0,0 +1,74 @@
+package irc
+
+import "fmt"
+
+// Numeric replies used by the client. Names follow RFC 2812 and the IRCv3 specs.
+const (
+	RPL_WELCOME          = "001"
+	RPL_ISUPPORT         = "005"
+	RPL_WHOSPCRPL        = "354"
+	RPL_NAMREPLY         = "353"
+	RPL_HOSTHIDDEN       = "396"
+	ERR_ERRONEUSNICKNAME = "432"
+	ERR_NICKNAMEINUSE    = "433"
+	ERR_NICKCOLLISION    = "436"
+	ERR_PASSWDMISMATCH   = "464"
+	ERR_YOUREBANNEDCREEP = "465"
+	RPL_LOGGEDIN         = "900"
+	ERR_NICKLOCKED       = "902"
+	RPL_SASLSUCCESS      = "903"
+	ERR_SASLFAIL         = "904"
+	ERR_SASLTOOLONG      = "905"
+	ERR_SASLABORTED      = "906"
+	RPL_SASLMECHS        = "908"
+)
+
+// numericNames maps numerics to their symbolic names for error messages.
+var numericNames = map[string]string{
+	RPL_WELCOME:          "RPL_WELCOME",
+	RPL_ISUPPORT:         "RPL_ISUPPORT",
+	RPL_WHOSPCRPL:        "RPL_WHOSPCRPL",
+	RPL_NAMREPLY:         "RPL_NAMREPLY",
+	RPL_HOSTHIDDEN:       "RPL_HOSTHIDDEN",
+	ERR_ERRONEUSNICKNAME: "ERR_ERRONEUSNICKNAME",
+	ERR_NICKNAMEINUSE:    "ERR_NICKNAMEINUSE",
+	ERR_NICKCOLLISION:    "ERR_NICKCOLLISION",
+	ERR_PASSWDMISMATCH:   "ERR_PASSWDMISMATCH",
+	ERR_YOUREBANNEDCREEP: "ERR_YOUREBANNEDCREEP",
+	RPL_LOGGEDIN:         "RPL_LOGGEDIN",
+	ERR_NICKLOCKED:       "ERR_NICKLOCKED",
+	RPL_SASLSUCCESS:      "RPL_SASLSUCCESS",
+	ERR_SASLFAIL:         "ERR_SASLFAIL",
+	ERR_SASLTOOLONG:      "ERR_SASLTOOLONG",
+	ERR_SASLABORTED:      "ERR_SASLABORTED",
+	RPL_SASLMECHS:        "RPL_SASLMECHS",
+}
+
+// registrationFailures are the numerics that abort connection registration.
+var registrationFailures = map[string]bool{
+	ERR_ERRONEUSNICKNAME: true,
+	ERR_NICKNAMEINUSE:    true,
+	ERR_NICKCOLLISION:    true,
+	ERR_PASSWDMISMATCH:   true,
+	ERR_YOUREBANNEDCREEP: true,
+}
+
+// RegistrationError reports that the server refused to register the connection.
+type RegistrationError struct {
+	Numeric string // The numeric that caused the failure, e.g. "433"
+	Reason  string // Human readable text sent by the server
+}
+
+func (e *RegistrationError) Error() string {
+	name := numericNames[e.Numeric]
+	if name == "" {
+		name = "unknown numeric"
+	}
+	return fmt.Sprintf("registration failed: %s %s: %s", e.Numeric, name, e.Reason)
+}
+
+// Retryable reports whether reconnecting later might succeed.
+// A nickname in use may be freed (e.g. our own ghost timing out); the others will not change.
+func (e *RegistrationError) Retryable() bool {
+	return e.Numeric == ERR_NICKNAMEINUSE || e.Numeric == ERR_NICKCOLLISION
+}
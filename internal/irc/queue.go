@@ -0,0 +1,190 @@
+package irc
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFloodBurst = 5   // Lines that may be sent back to back
+	defaultFloodRate  = 0.5 // Lines per second once the burst is used up
+	maxQueuedLines    = 100 // Normal-priority lines kept before new ones are dropped
+)
+
+// Priority orders outgoing lines in the send queue.
+type Priority int
+
+const (
+	PriorityHigh   Priority = iota // Keepalives and registration; never wait for the rate limiter
+	PriorityNormal                 // Everything else, in order, subject to flood control
+)
+
+// priorityFor picks the queue priority for a raw line based on its command.
+func priorityFor(line string) Priority {
+	command, _, _ := strings.Cut(line, " ")
+	switch strings.ToUpper(command) {
+	case "PONG", "PING", "CAP", "AUTHENTICATE", "NICK", "USER", "PASS", "QUIT":
+		return PriorityHigh
+	}
+	return PriorityNormal
+}
+
+// sendQueue is a two-level priority queue of outgoing lines consumed by a single writer.
+type sendQueue struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	high      []string
+	normal    []string
+	inFlight  int           // Lines popped by the writer but not yet written
+	highReady chan struct{} // Wakes a writer waiting on the rate limiter
+	closed    bool
+}
+
+func newSendQueue() *sendQueue {
+	q := &sendQueue{highReady: make(chan struct{}, 1)}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push adds a line to the queue. It returns false if the queue is closed or full.
+func (q *sendQueue) push(line string, p Priority) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return false
+	}
+	if p == PriorityHigh {
+		q.high = append(q.high, line)
+		select {
+		case q.highReady <- struct{}{}:
+		default:
+		}
+	} else {
+		if len(q.normal) >= maxQueuedLines {
+			return false
+		}
+		q.normal = append(q.normal, line)
+	}
+	q.cond.Broadcast()
+	return true
+}
+
+// pop blocks until a line is available and returns it with its priority.
+// It returns false once the queue has been closed.
+func (q *sendQueue) pop() (string, Priority, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.high) == 0 && len(q.normal) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return "", PriorityNormal, false
+	}
+	q.inFlight++
+	if len(q.high) > 0 {
+		line := q.high[0]
+		q.high = q.high[1:]
+		return line, PriorityHigh, true
+	}
+	line := q.normal[0]
+	q.normal = q.normal[1:]
+	return line, PriorityNormal, true
+}
+
+// done marks a popped line as written.
+func (q *sendQueue) done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.inFlight--
+	q.cond.Broadcast()
+}
+
+// requeueFront puts a normal line back at the head of the queue so a
+// high-priority line can overtake it while the writer waits for a token.
+func (q *sendQueue) requeueFront(line string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.inFlight--
+	q.normal = append([]string{line}, q.normal...)
+	q.cond.Broadcast()
+}
+
+// waitEmpty blocks until everything queued has been written, the queue is
+// closed, or the timeout passes. It reports whether the queue drained.
+func (q *sendQueue) waitEmpty(timeout time.Duration) bool {
+	drained := make(chan bool, 1)
+	go func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		for (len(q.high) > 0 || len(q.normal) > 0 || q.inFlight > 0) && !q.closed {
+			q.cond.Wait()
+		}
+		drained <- len(q.high) == 0 && len(q.normal) == 0 && q.inFlight == 0
+	}()
+	select {
+	case ok := <-drained:
+		return ok
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// close wakes the writer and any waiters; queued lines are discarded.
+func (q *sendQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// tokenBucket is a simple token-bucket rate limiter.
+type tokenBucket struct {
+	capacity float64
+	tokens   float64
+	rate     float64 // Tokens added per second
+	last     time.Time
+	now      func() time.Time
+}
+
+func newTokenBucket(burst int, rate float64) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(burst),
+		tokens:   float64(burst),
+		rate:     rate,
+		now:      time.Now,
+		last:     time.Now(),
+	}
+}
+
+// refill adds the tokens earned since the last call.
+func (b *tokenBucket) refill() {
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+}
+
+// take consumes a token if one is available. Otherwise it returns how long
+// to wait before one will be.
+func (b *tokenBucket) take() time.Duration {
+	b.refill()
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	if b.rate <= 0 {
+		return time.Second
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// takeNoWait consumes a token if one is available but never makes the caller wait.
+func (b *tokenBucket) takeNoWait() {
+	b.refill()
+	if b.tokens >= 1 {
+		b.tokens--
+	}
+}
@@ -0,0 +1,197 @@
+package irc
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"trebek/internal/config"
+)
+
+func TestPriorityFor(t *testing.T) {
+	tests := map[string]Priority{
+		"PONG :irc.example.com":   PriorityHigh,
+		"pong :x":                 PriorityHigh,
+		"NICK TestBot":            PriorityHigh,
+		"AUTHENTICATE +":          PriorityHigh,
+		"QUIT :bye":               PriorityHigh,
+		"PRIVMSG #c :hello":       PriorityNormal,
+		"JOIN #c":                 PriorityNormal,
+		"PRIVMSG #c :PONG :fake":  PriorityNormal,
+		"NOTICE someone :message": PriorityNormal,
+	}
+	for line, want := range tests {
+		if got := priorityFor(line); got != want {
+			t.Errorf("priorityFor(%q) = %d, want %d", line, got, want)
+		}
+	}
+}
+
+func TestSendQueueOrder(t *testing.T) {
+	q := newSendQueue()
+	q.push("PRIVMSG #c :one", PriorityNormal)
+	q.push("PRIVMSG #c :two", PriorityNormal)
+	q.push("PONG :x", PriorityHigh)
+
+	want := []string{"PONG :x", "PRIVMSG #c :one", "PRIVMSG #c :two"}
+	for _, w := range want {
+		line, _, ok := q.pop()
+		if !ok || line != w {
+			t.Fatalf("pop() = %q, %t; want %q", line, ok, w)
+		}
+		q.done()
+	}
+
+	q.close()
+	if _, _, ok := q.pop(); ok {
+		t.Error("pop() on a closed queue should return false")
+	}
+	if q.push("PRIVMSG #c :late", PriorityNormal) {
+		t.Error("push() on a closed queue should fail")
+	}
+}
+
+func TestSendQueueLimit(t *testing.T) {
+	q := newSendQueue()
+	for i := 0; i < maxQueuedLines; i++ {
+		if !q.push("PRIVMSG #c :x", PriorityNormal) {
+			t.Fatalf("push %d failed before the limit", i)
+		}
+	}
+	if q.push("PRIVMSG #c :overflow", PriorityNormal) {
+		t.Error("Expected push beyond the limit to fail")
+	}
+	if !q.push("PONG :x", PriorityHigh) {
+		t.Error("High priority lines should still be accepted when the queue is full")
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTokenBucket(2, 1)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	if b.take() != 0 || b.take() != 0 {
+		t.Fatal("Expected the burst to be available immediately")
+	}
+	if wait := b.take(); wait != time.Second {
+		t.Errorf("Expected to wait 1s for the next token, got %s", wait)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if wait := b.take(); wait != 500*time.Millisecond {
+		t.Errorf("Expected to wait 500ms after half a token refilled, got %s", wait)
+	}
+
+	now = now.Add(10 * time.Second)
+	if b.take() != 0 || b.take() != 0 {
+		t.Error("Expected the bucket to refill up to its burst")
+	}
+	if b.take() == 0 {
+		t.Error("Bucket should never hold more than its capacity")
+	}
+}
+
+func TestFloodControl(t *testing.T) {
+	cfg := &config.Config{
+		BotName:    "TestBot",
+		IRCServer:  "irc.example.com:6667",
+		FloodBurst: 4, // CAP, NICK and USER use three of these
+		FloodRate:  20,
+	}
+	client := NewClient(cfg)
+	mockConn := NewMockConn()
+	mockDial = func(network, address string) (net.Conn, error) {
+		return mockConn, nil
+	}
+	defer func() { mockDial = nil }()
+
+	if err := client.Connect(false); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+	client.Flush(time.Second)
+	mockConn.ClearWrittenData()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		client.Send("PRIVMSG #c :line %d", i)
+	}
+	client.Send("PONG :urgent")
+
+	// The PONG overtakes the rate-limited PRIVMSGs still waiting in the queue.
+	deadline := time.After(time.Second)
+	for !strings.Contains(mockConn.GetWrittenData(), "PONG :urgent") {
+		select {
+		case <-deadline:
+			t.Fatalf("PONG was never written; got %q", mockConn.GetWrittenData())
+		case <-time.After(time.Millisecond):
+		}
+	}
+	written := mockConn.GetWrittenData()
+	if strings.Contains(written, "line 4") {
+		t.Errorf("PONG should not wait behind the whole backlog, got %q", written)
+	}
+
+	if !client.Flush(2 * time.Second) {
+		t.Fatal("Queue did not drain")
+	}
+	// One token was left over from the burst, so 4 lines had to wait 50ms each.
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Lines were sent too quickly (%s); rate limit not applied", elapsed)
+	}
+	for i := 0; i < 5; i++ {
+		if !strings.Contains(mockConn.GetWrittenData(), fmt.Sprintf("PRIVMSG #c :line %d\r\n", i)) {
+			t.Errorf("line %d missing from %q", i, mockConn.GetWrittenData())
+		}
+	}
+}
+
+func TestConcurrentSend(t *testing.T) {
+	cfg := &config.Config{
+		BotName:    "TestBot",
+		IRCServer:  "irc.example.com:6667",
+		FloodBurst: 100,
+		FloodRate:  1000,
+	}
+	client := NewClient(cfg)
+	mockConn := NewMockConn()
+	mockDial = func(network, address string) (net.Conn, error) {
+		return mockConn, nil
+	}
+	defer func() { mockDial = nil }()
+
+	if err := client.Connect(false); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+	client.Flush(time.Second)
+	mockConn.ClearWrittenData()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				client.Send("PRIVMSG #c :goroutine %d message %d", g, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	client.Flush(2 * time.Second)
+
+	lines := strings.Split(strings.TrimSuffix(mockConn.GetWrittenData(), "\r\n"), "\r\n")
+	if len(lines) != 80 {
+		t.Fatalf("Expected 80 intact lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "PRIVMSG #c :goroutine ") {
+			t.Errorf("Interleaved or corrupt line: %q", line)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package irc
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxLineLength = 512 // RFC 1459 limit, including the trailing CRLF
+	maxUserLength = 10  // Typical USERLEN plus the "~" ident prefix
+	maxHostLength = 63  // Longest hostname label servers commonly allow
+)
+
+// IRC formatting control codes.
+const (
+	fmtBold      = '\x02'
+	fmtColor     = '\x03'
+	fmtMonospace = '\x11'
+	fmtReverse   = '\x16'
+	fmtItalic    = '\x1d'
+	fmtStrike    = '\x1e'
+	fmtUnderline = '\x1f'
+	fmtReset     = '\x0f'
+)
+
+// formatState tracks which formatting is active at a point in a message so it
+// can be restored at the start of the next line after a split.
+type formatState struct {
+	bold, italic, underline, strike, monospace, reverse bool
+	fg, bg                                              string // Colour numbers; "" when unset
+}
+
+// scan updates the state with every control code in text.
+func (s *formatState) scan(text string) {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case fmtBold:
+			s.bold = !s.bold
+		case fmtItalic:
+			s.italic = !s.italic
+		case fmtUnderline:
+			s.underline = !s.underline
+		case fmtStrike:
+			s.strike = !s.strike
+		case fmtMonospace:
+			s.monospace = !s.monospace
+		case fmtReverse:
+			s.reverse = !s.reverse
+		case fmtReset:
+			*s = formatState{}
+		case fmtColor:
+			fg, bg, n := parseColor(text[i+1:])
+			if fg == "" {
+				s.fg, s.bg = "", "" // A bare \x03 clears colours
+			} else {
+				s.fg = fg
+				if bg != "" {
+					s.bg = bg
+				}
+			}
+			i += n
+		}
+	}
+}
+
+// codes returns the control codes that recreate the state on a fresh line.
+func (s formatState) codes() string {
+	var b strings.Builder
+	if s.fg != "" {
+		b.WriteByte(fmtColor)
+		b.WriteString(s.fg)
+		if s.bg != "" {
+			b.WriteByte(',')
+			b.WriteString(s.bg)
+		}
+	}
+	for _, f := range []struct {
+		on   bool
+		code byte
+	}{
+		{s.bold, fmtBold},
+		{s.italic, fmtItalic},
+		{s.underline, fmtUnderline},
+		{s.strike, fmtStrike},
+		{s.monospace, fmtMonospace},
+		{s.reverse, fmtReverse},
+	} {
+		if f.on {
+			b.WriteByte(f.code)
+		}
+	}
+	return b.String()
+}
+
+// parseColor reads the "fg[,bg]" digits that follow a \x03 code and returns
+// them along with the number of bytes consumed.
+func parseColor(s string) (fg, bg string, n int) {
+	fg = leadingDigits(s, 2)
+	n = len(fg)
+	if fg != "" && n < len(s) && s[n] == ',' {
+		if bg = leadingDigits(s[n+1:], 2); bg != "" {
+			n += 1 + len(bg)
+		}
+	}
+	return fg, bg, n
+}
+
+// leadingDigits returns up to max ASCII digits from the start of s.
+func leadingDigits(s string, max int) string {
+	i := 0
+	for i < len(s) && i < max && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
+
+// atomLen returns the byte length of the unit starting at s[0] that must not be
+// split: a whole colour code with its digits, or a single UTF-8 rune.
+func atomLen(s string) int {
+	if s[0] == fmtColor {
+		_, _, n := parseColor(s[1:])
+		return 1 + n
+	}
+	_, size := utf8.DecodeRuneInString(s)
+	return size
+}
+
+// cutPoint returns how many bytes of s fit in room without splitting an atom.
+// It always returns at least one atom so callers make progress.
+func cutPoint(s string, room int) int {
+	cut := 0
+	for cut < len(s) {
+		n := atomLen(s[cut:])
+		if cut+n > room && cut > 0 {
+			break
+		}
+		cut += n
+		if cut >= room {
+			break
+		}
+	}
+	return cut
+}
+
+// splitMessage breaks text into lines of at most limit bytes. It prefers to
+// break between words, never splits a UTF-8 rune or colour code, and starts
+// each continuation line with the formatting that was active where the
+// previous one ended. Embedded newlines always start a new line.
+func splitMessage(text string, limit int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		lines = append(lines, splitLine(strings.TrimRight(paragraph, "\r"), limit)...)
+	}
+	return lines
+}
+
+// splitLine implements splitMessage for text without newlines.
+func splitLine(text string, limit int) []string {
+	if len(text) <= limit {
+		return []string{text}
+	}
+
+	var lines []string
+	var line strings.Builder
+	var state formatState
+	hasContent := false
+
+	flush := func() {
+		lines = append(lines, line.String())
+		line.Reset()
+		line.WriteString(state.codes())
+		hasContent = false
+	}
+
+	for _, word := range strings.Split(text, " ") {
+		sep := " "
+		if !hasContent {
+			sep = ""
+		}
+		if line.Len()+len(sep)+len(word) <= limit {
+			line.WriteString(sep)
+			line.WriteString(word)
+			state.scan(word)
+			hasContent = true
+			continue
+		}
+
+		if hasContent {
+			flush()
+		}
+		// The word does not fit on a line of its own; hard-split it.
+		for len(word) > 0 {
+			room := limit - line.Len()
+			if len(word) <= room {
+				line.WriteString(word)
+				state.scan(word)
+				hasContent = true
+				break
+			}
+			cut := cutPoint(word, room)
+			line.WriteString(word[:cut])
+			state.scan(word[:cut])
+			word = word[cut:]
+			flush()
+		}
+	}
+	if hasContent {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// messageRoom returns how many bytes of text fit in a command sent to target,
+// allowing for the ":nick!user@host COMMAND target :" prefix the server adds
+// when relaying it.
+func (c *Client) messageRoom(command, target string) int {
+	overhead := len(":") + len(c.hostmask()) + len(" "+command+" "+target+" :") + len("\r\n")
+	return maxLineLength - overhead
+}
+
+// hostmask returns our nick!user@host as other clients see it, estimating the
+// longest plausible user and host until the server has told us the real ones.
+func (c *Client) hostmask() string {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	user, host := c.user, c.host
+	if user == "" {
+		user = strings.Repeat("u", maxUserLength)
+	}
+	if host == "" {
+		host = strings.Repeat("h", maxHostLength)
+	}
+	return c.nick + "!" + user + "@" + host
+}
+
+// setUserHost records our user and host as reported by the server.
+func (c *Client) setUserHost(user, host string) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	if user != "" {
+		c.user = user
+	}
+	if host != "" {
+		c.host = host
+	}
+}
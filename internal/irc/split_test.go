@@ -0,0 +1,164 @@
+package irc
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"trebek/internal/config"
+)
+
+func TestSplitMessageShort(t *testing.T) {
+	lines := splitMessage("Hello, world!", 100)
+	if len(lines) != 1 || lines[0] != "Hello, world!" {
+		t.Errorf("Short messages should be untouched, got %q", lines)
+	}
+}
+
+func TestSplitMessageWords(t *testing.T) {
+	lines := splitMessage("the quick brown fox jumps over the lazy dog", 15)
+	want := []string{"the quick brown", "fox jumps over", "the lazy dog"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+	for _, l := range lines {
+		if len(l) > 15 {
+			t.Errorf("line %q exceeds the limit", l)
+		}
+	}
+}
+
+func TestSplitMessageLongWord(t *testing.T) {
+	lines := splitMessage("short "+strings.Repeat("x", 25), 10)
+	want := []string{"short", "xxxxxxxxxx", "xxxxxxxxxx", "xxxxx"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+}
+
+func TestSplitMessageUTF8(t *testing.T) {
+	text := strings.Repeat("Dvořák ", 10) + strings.Repeat("日本語", 10)
+	lines := splitMessage(text, 11)
+	var rejoined strings.Builder
+	for _, l := range lines {
+		if !utf8.ValidString(l) {
+			t.Errorf("line %q splits a rune", l)
+		}
+		if len(l) > 11 {
+			t.Errorf("line %q exceeds the limit", l)
+		}
+		rejoined.WriteString(l)
+	}
+	if strings.ReplaceAll(rejoined.String(), " ", "") != strings.ReplaceAll(text, " ", "") {
+		t.Errorf("content lost while splitting: %q", lines)
+	}
+}
+
+func TestSplitMessageFormatting(t *testing.T) {
+	t.Run("Bold", func(t *testing.T) {
+		lines := splitMessage("\x02bold words carry over\x02 plain", 12)
+		if len(lines) < 2 {
+			t.Fatalf("expected a split, got %q", lines)
+		}
+		if !strings.HasPrefix(lines[1], "\x02") {
+			t.Errorf("continuation should reopen bold, got %q", lines[1])
+		}
+		last := lines[len(lines)-1]
+		if strings.HasPrefix(last, "\x02") && strings.HasSuffix(last, "plain") && !strings.Contains(last[1:], "\x02") {
+			t.Errorf("bold should not be carried past where it was closed, got %q", last)
+		}
+	})
+
+	t.Run("Colour", func(t *testing.T) {
+		lines := splitMessage("\x0304,12red on blue text here", 14)
+		if len(lines) < 2 {
+			t.Fatalf("expected a split, got %q", lines)
+		}
+		if !strings.HasPrefix(lines[1], "\x0304,12") {
+			t.Errorf("continuation should restore colours, got %q", lines[1])
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		lines := splitMessage("\x02\x1dstyled\x0f plain words follow", 14)
+		for _, l := range lines[1:] {
+			if strings.ContainsAny(l, "\x02\x1d") {
+				t.Errorf("formatting after reset should not be carried, got %q", l)
+			}
+		}
+	})
+
+	t.Run("ColourCodeNotSplit", func(t *testing.T) {
+		lines := splitMessage("abcdefgh\x0304,12ij", 10)
+		for _, l := range lines {
+			if strings.HasSuffix(l, "\x03") || strings.HasSuffix(l, "\x030") || strings.HasSuffix(l, "\x0304,") {
+				t.Errorf("colour code split across lines: %q", lines)
+			}
+		}
+	})
+}
+
+func TestSplitMessageNewlines(t *testing.T) {
+	lines := splitMessage("first\r\nsecond\nthird", 100)
+	if strings.Join(lines, "|") != "first|second|third" {
+		t.Errorf("newlines should start new lines, got %q", lines)
+	}
+}
+
+func TestPrivmsgSplitsLongMessages(t *testing.T) {
+	cfg := &config.Config{BotName: "TestBot", IRCServer: "irc.example.com:6667", FloodBurst: 50}
+	client := NewClient(cfg)
+	mockConn := NewMockConn()
+	mockDial = func(network, address string) (net.Conn, error) {
+		return mockConn, nil
+	}
+	defer func() { mockDial = nil }()
+
+	if err := client.Connect(false); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+	mockConn.InjectReadData(":irc.example.com 001 TestBot :Welcome\r\n")
+	if err := client.Register(); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	client.dispatch(mustParse(t, ":TestBot!~trebek@bot.example.com JOIN #trivia"))
+	client.Flush(time.Second)
+	mockConn.ClearWrittenData()
+
+	clue := strings.Repeat("This clue goes on and on. ", 40)
+	client.Privmsg("#trivia", clue)
+	client.Flush(time.Second)
+
+	relayPrefix := ":TestBot!~trebek@bot.example.com "
+	sent := strings.Split(strings.TrimSuffix(mockConn.GetWrittenData(), "\r\n"), "\r\n")
+	if len(sent) < 2 {
+		t.Fatalf("Expected the clue to be split, got %d line(s)", len(sent))
+	}
+	var text []string
+	for _, line := range sent {
+		if len(relayPrefix)+len(line)+2 > maxLineLength {
+			t.Errorf("relayed line would be %d bytes: %q", len(relayPrefix)+len(line)+2, line)
+		}
+		text = append(text, strings.TrimPrefix(line, "PRIVMSG #trivia :"))
+	}
+	if strings.Join(text, " ") != clue {
+		t.Error("Split lines do not add up to the original message")
+	}
+}
+
+func TestHostmaskEstimate(t *testing.T) {
+	client := NewClient(&config.Config{BotName: "TestBot"})
+	estimated := client.messageRoom("PRIVMSG", "#c")
+
+	client.setUserHost("~t", "h")
+	known := client.messageRoom("PRIVMSG", "#c")
+	if estimated >= known {
+		t.Errorf("Unknown hostmask should be estimated pessimistically: estimated %d, known %d", estimated, known)
+	}
+	if want := maxLineLength - len(":TestBot!~t@h PRIVMSG #c :\r\n"); known != want {
+		t.Errorf("messageRoom = %d, want %d", known, want)
+	}
+}
@@ -0,0 +1,347 @@
+package irc
+
+import (
+	"strings"
+	"sync"
+)
+
+const (
+	defaultPrefixModes   = "ov" // RFC 1459 channel status modes, until ISUPPORT says otherwise
+	defaultPrefixSymbols = "@+"
+	whoxToken            = "152" // Marks replies to our own WHOX queries
+)
+
+// UserInfo is what the client has learned about another user from the
+// messages it has seen.
+type UserInfo struct {
+	Nick    string
+	User    string
+	Host    string
+	Account string // Services account; empty when not logged in or unknown
+}
+
+// Hostmask returns the user's nick!user@host.
+func (u UserInfo) Hostmask() string {
+	return (&Prefix{Name: u.Nick, User: u.User, Host: u.Host}).String()
+}
+
+// userTracker follows channel membership, channel status and services
+// accounts so commands can be authorized without querying the server.
+type userTracker struct {
+	mu            sync.Mutex
+	prefixModes   string                       // Status modes in rank order, e.g. "qaohv"
+	prefixSymbols string                       // Matching prefixes, e.g. "~&@%+"
+	paramModes    string                       // Non-status channel modes that always take a parameter
+	setParamModes string                       // Channel modes that take a parameter only when set
+	whox          bool                         // Server supports WHOX
+	users         map[string]*UserInfo         // Keyed by lowercased nick
+	members       map[string]map[string]string // Channel -> nick -> status prefixes, all lowercased keys
+}
+
+func newUserTracker() *userTracker {
+	t := &userTracker{}
+	t.reset()
+	return t
+}
+
+// reset forgets everything, ready for a new connection.
+func (t *userTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prefixModes = defaultPrefixModes
+	t.prefixSymbols = defaultPrefixSymbols
+	t.paramModes = "bkeI"
+	t.setParamModes = "l"
+	t.whox = false
+	t.users = make(map[string]*UserInfo)
+	t.members = make(map[string]map[string]string)
+}
+
+// user returns the tracked user, creating it if needed. The caller must hold mu.
+func (t *userTracker) user(nick string) *UserInfo {
+	key := strings.ToLower(nick)
+	u, ok := t.users[key]
+	if !ok {
+		u = &UserInfo{Nick: nick}
+		t.users[key] = u
+	}
+	return u
+}
+
+// isupport applies the RPL_ISUPPORT tokens we care about.
+func (t *userTracker) isupport(tokens []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, token := range tokens {
+		key, value, _ := strings.Cut(token, "=")
+		switch strings.ToUpper(key) {
+		case "PREFIX":
+			// PREFIX=(qaohv)~&@%+
+			modes, symbols, ok := strings.Cut(strings.TrimPrefix(value, "("), ")")
+			if ok && len(modes) == len(symbols) {
+				t.prefixModes, t.prefixSymbols = modes, symbols
+			}
+		case "CHANMODES":
+			// CHANMODES=A,B,C,D: lists, always-parameter, set-only parameter, flags
+			groups := strings.Split(value, ",")
+			if len(groups) >= 3 {
+				t.paramModes = groups[0] + groups[1]
+				t.setParamModes = groups[2]
+			}
+		case "WHOX":
+			t.whox = true
+		}
+	}
+}
+
+// seen records the user and host from a message source, and the account tag
+// if the server attached one.
+func (t *userTracker) seen(msg *Message) {
+	if msg.Source == nil || msg.Source.Host == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u := t.user(msg.Source.Name)
+	u.User, u.Host = msg.Source.User, msg.Source.Host
+	if account, ok := msg.Tag("account"); ok {
+		u.Account = account
+	}
+}
+
+// setAccount records a services account; "*" or "0" means logged out.
+func (t *userTracker) setAccount(nick, account string) {
+	if account == "*" || account == "0" {
+		account = ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.user(nick).Account = account
+}
+
+// join adds nick to channel. When it is us, any stale membership is dropped
+// because NAMES will list everyone again.
+func (t *userTracker) join(channel, nick string, me bool) {
+	key := strings.ToLower(channel)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if me || t.members[key] == nil {
+		t.members[key] = make(map[string]string)
+	}
+	t.members[key][strings.ToLower(nick)] = ""
+	t.user(nick)
+}
+
+// part removes nick from channel, or forgets the channel when it is us.
+func (t *userTracker) part(channel, nick string, me bool) {
+	key := strings.ToLower(channel)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if me {
+		delete(t.members, key)
+	} else {
+		delete(t.members[key], strings.ToLower(nick))
+	}
+	t.pruneLocked(nick)
+}
+
+// quit removes nick from every channel.
+func (t *userTracker) quit(nick string) {
+	key := strings.ToLower(nick)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, members := range t.members {
+		delete(members, key)
+	}
+	delete(t.users, key)
+}
+
+// rename moves everything known about oldNick to newNick.
+func (t *userTracker) rename(oldNick, newNick string) {
+	oldKey, newKey := strings.ToLower(oldNick), strings.ToLower(newNick)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, members := range t.members {
+		if status, ok := members[oldKey]; ok {
+			delete(members, oldKey)
+			members[newKey] = status
+		}
+	}
+	if u, ok := t.users[oldKey]; ok {
+		delete(t.users, oldKey)
+		u.Nick = newNick
+		t.users[newKey] = u
+	}
+}
+
+// pruneLocked forgets a user who shares no channel with us any more.
+func (t *userTracker) pruneLocked(nick string) {
+	key := strings.ToLower(nick)
+	for _, members := range t.members {
+		if _, ok := members[key]; ok {
+			return
+		}
+	}
+	delete(t.users, key)
+}
+
+// names adds the members listed in an RPL_NAMREPLY, e.g. "@alice +bob carol".
+// With multi-prefix a member may carry several prefixes, e.g. "@+alice".
+func (t *userTracker) names(channel, list string) {
+	key := strings.ToLower(channel)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.members[key] == nil {
+		t.members[key] = make(map[string]string)
+	}
+	for _, entry := range strings.Fields(list) {
+		nick := strings.TrimLeft(entry, t.prefixSymbols)
+		if nick == "" {
+			continue
+		}
+		status := entry[:len(entry)-len(nick)]
+		t.members[key][strings.ToLower(nick)] = status
+		t.user(nick)
+	}
+}
+
+// mode applies the status changes in a channel MODE, e.g. "+ov-v alice bob carol".
+func (t *userTracker) mode(channel string, params []string) {
+	if len(params) == 0 {
+		return
+	}
+	key := strings.ToLower(channel)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	members, ok := t.members[key]
+	if !ok {
+		return // A user mode, or a channel we are not in
+	}
+
+	adding := true
+	args := params[1:]
+	for _, m := range params[0] {
+		switch {
+		case m == '+':
+			adding = true
+		case m == '-':
+			adding = false
+		case strings.ContainsRune(t.prefixModes, m):
+			if len(args) == 0 {
+				return
+			}
+			nick := strings.ToLower(args[0])
+			args = args[1:]
+			if status, ok := members[nick]; ok {
+				members[nick] = t.applyStatus(status, m, adding)
+			}
+		case strings.ContainsRune(t.paramModes, m), adding && strings.ContainsRune(t.setParamModes, m):
+			if len(args) > 0 {
+				args = args[1:]
+			}
+		}
+	}
+}
+
+// applyStatus adds or removes the prefix for mode m, keeping prefixes in rank order.
+func (t *userTracker) applyStatus(status string, m rune, adding bool) string {
+	symbol := t.prefixSymbols[strings.IndexRune(t.prefixModes, m)]
+	var b strings.Builder
+	for i := 0; i < len(t.prefixSymbols); i++ {
+		s := t.prefixSymbols[i]
+		has := strings.IndexByte(status, s) >= 0
+		if s == symbol {
+			has = adding
+		}
+		if has {
+			b.WriteByte(s)
+		}
+	}
+	return b.String()
+}
+
+// whoxReply records a reply to "WHO <channel> %tuhna,<token>":
+// <me> <token> <user> <host> <nick> <account>.
+func (t *userTracker) whoxReply(params []string) {
+	if len(params) < 6 || params[1] != whoxToken {
+		return
+	}
+	account := params[5]
+	if account == "0" {
+		account = ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u := t.user(params[4])
+	u.User, u.Host, u.Account = params[2], params[3], account
+}
+
+// LookupUser returns what is known about a user who shares a channel with the bot.
+func (c *Client) LookupUser(nick string) (UserInfo, bool) {
+	c.users.mu.Lock()
+	defer c.users.mu.Unlock()
+	u, ok := c.users.users[strings.ToLower(nick)]
+	if !ok {
+		return UserInfo{Nick: nick}, false
+	}
+	return *u, true
+}
+
+// ChannelStatus returns the status prefixes nick holds in channel, highest
+// first, e.g. "@" for an operator or "@+" for a voiced operator.
+func (c *Client) ChannelStatus(channel, nick string) string {
+	c.users.mu.Lock()
+	defer c.users.mu.Unlock()
+	return c.users.members[strings.ToLower(channel)][strings.ToLower(nick)]
+}
+
+// OnChannel reports whether nick is on channel, as far as the bot can see.
+func (c *Client) OnChannel(channel, nick string) bool {
+	c.users.mu.Lock()
+	defer c.users.mu.Unlock()
+	_, ok := c.users.members[strings.ToLower(channel)][strings.ToLower(nick)]
+	return ok
+}
+
+// trackUsers updates the user tracker from a message.
+func (c *Client) trackUsers(msg *Message) {
+	t := c.users
+	t.seen(msg)
+
+	switch msg.Command {
+	case RPL_ISUPPORT:
+		if len(msg.Params) > 2 {
+			t.isupport(msg.Params[1 : len(msg.Params)-1])
+		}
+	case RPL_NAMREPLY:
+		t.names(msg.Param(2), msg.Trailing())
+	case RPL_WHOSPCRPL:
+		t.whoxReply(msg.Params)
+	case "JOIN":
+		me := c.IsMe(msg.Nick())
+		t.join(msg.Param(0), msg.Nick(), me)
+		if len(msg.Params) >= 3 { // extended-join: <channel> <account> :<realname>
+			t.setAccount(msg.Nick(), msg.Param(1))
+		}
+		t.mu.Lock()
+		whox := t.whox
+		t.mu.Unlock()
+		if me && whox {
+			c.Send("WHO %s %%tuhna,%s", msg.Param(0), whoxToken)
+		}
+	case "PART":
+		t.part(msg.Param(0), msg.Nick(), c.IsMe(msg.Nick()))
+	case "KICK":
+		t.part(msg.Param(0), msg.Param(1), c.IsMe(msg.Param(1)))
+	case "QUIT":
+		t.quit(msg.Nick())
+	case "NICK":
+		t.rename(msg.Nick(), msg.Param(0))
+	case "ACCOUNT":
+		t.setAccount(msg.Nick(), msg.Param(0))
+	case "MODE":
+		if len(msg.Params) > 1 {
+			t.mode(msg.Param(0), msg.Params[1:])
+		}
+	}
+}
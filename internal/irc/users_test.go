@@ -0,0 +1,133 @@
+package irc
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"trebek/internal/config"
+)
+
+func TestUserTracking(t *testing.T) {
+	cfg := &config.Config{BotName: "TestBot", IRCServer: "irc.example.com:6667"}
+	client := NewClient(cfg)
+	feed := func(lines ...string) {
+		for _, line := range lines {
+			client.dispatch(mustParse(t, line))
+		}
+	}
+
+	feed(
+		":irc.example.com 005 TestBot PREFIX=(qaohv)~&@%+ CHANMODES=beI,k,l,imnst :are supported by this server",
+		":TestBot!bot@bot.host JOIN #trivia",
+		":irc.example.com 353 TestBot = #trivia :TestBot ~owner @+alice %bob carol",
+	)
+
+	statusTests := map[string]string{"owner": "~", "alice": "@+", "bob": "%", "carol": "", "nobody": ""}
+	for nick, expected := range statusTests {
+		if got := client.ChannelStatus("#Trivia", nick); got != expected {
+			t.Errorf("ChannelStatus(%s) = %q, expected %q", nick, got, expected)
+		}
+	}
+	if !client.OnChannel("#Trivia", "Carol") || client.OnChannel("#trivia", "nobody") {
+		t.Error("Expected carol, and not nobody, to be on #trivia")
+	}
+
+	t.Run("Modes", func(t *testing.T) {
+		feed(":alice!a@a.host MODE #trivia +ko-v secret carol alice")
+		if got := client.ChannelStatus("#trivia", "carol"); got != "@" {
+			t.Errorf("Expected carol to be opped, got %q", got)
+		}
+		if got := client.ChannelStatus("#trivia", "alice"); got != "@" {
+			t.Errorf("Expected alice to lose voice, got %q", got)
+		}
+		feed(":alice!a@a.host MODE #trivia +lh-o 10 carol carol")
+		if got := client.ChannelStatus("#trivia", "carol"); got != "%" {
+			t.Errorf("Expected carol to be a half-op, got %q", got)
+		}
+		feed(":alice!a@a.host MODE TestBot +i") // User modes are ignored
+	})
+
+	t.Run("Accounts", func(t *testing.T) {
+		feed(
+			"@account=alice_acct :alice!a@a.host PRIVMSG #trivia :hi",
+			":dave!d@d.host JOIN #trivia dave_acct :Dave",
+			":erin!e@e.host JOIN #trivia * :Erin",
+			":erin!e@e.host ACCOUNT erin_acct",
+		)
+		for nick, expected := range map[string]string{"alice": "alice_acct", "dave": "dave_acct", "erin": "erin_acct"} {
+			u, ok := client.LookupUser(nick)
+			if !ok || u.Account != expected {
+				t.Errorf("LookupUser(%s) = %+v, %v; expected account %q", nick, u, ok, expected)
+			}
+		}
+		u, _ := client.LookupUser("Alice")
+		if u.Hostmask() != "alice!a@a.host" {
+			t.Errorf("Expected hostmask alice!a@a.host, got %q", u.Hostmask())
+		}
+		feed(":erin!e@e.host ACCOUNT *")
+		if u, _ := client.LookupUser("erin"); u.Account != "" {
+			t.Errorf("Expected erin to be logged out, got %q", u.Account)
+		}
+	})
+
+	t.Run("NickPartQuit", func(t *testing.T) {
+		feed(":alice!a@a.host NICK alicia")
+		if got := client.ChannelStatus("#trivia", "alicia"); got != "@" {
+			t.Errorf("Expected status to follow the nick change, got %q", got)
+		}
+		if u, ok := client.LookupUser("alicia"); !ok || u.Account != "alice_acct" {
+			t.Errorf("Expected account to follow the nick change, got %+v", u)
+		}
+		if _, ok := client.LookupUser("alice"); ok {
+			t.Error("Old nick should be forgotten")
+		}
+
+		feed(":bob!b@b.host PART #trivia :bye")
+		if _, ok := client.LookupUser("bob"); ok {
+			t.Error("Expected bob to be forgotten after leaving our only channel")
+		}
+		feed(":alicia!a@a.host KICK #trivia carol :out")
+		if got := client.ChannelStatus("#trivia", "carol"); got != "" {
+			t.Errorf("Expected carol to be gone after a kick, got %q", got)
+		}
+		feed(":dave!d@d.host QUIT :gone")
+		if _, ok := client.LookupUser("dave"); ok {
+			t.Error("Expected dave to be forgotten after quitting")
+		}
+	})
+}
+
+func TestWHOXOnJoin(t *testing.T) {
+	cfg := &config.Config{BotName: "TestBot", IRCServer: "irc.example.com:6667"}
+	client, mockConn := newScriptedClient(t, cfg, func(line string) string {
+		if strings.HasPrefix(line, "USER ") {
+			return ":irc.example.com 001 TestBot :Welcome\r\n"
+		}
+		return ""
+	})
+	defer client.Close()
+	if err := client.Register(); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	client.dispatch(mustParse(t, ":irc.example.com 005 TestBot WHOX :are supported by this server"))
+
+	client.dispatch(mustParse(t, ":TestBot!bot@bot.host JOIN #trivia"))
+	client.Flush(time.Second)
+	if !strings.Contains(mockConn.GetWrittenData(), "WHO #trivia %tuhna,152\r\n") {
+		t.Errorf("Expected a WHOX query after joining, got %q", mockConn.GetWrittenData())
+	}
+
+	client.dispatch(mustParse(t, ":irc.example.com 354 TestBot 152 frank f.host frank frank_acct"))
+	client.dispatch(mustParse(t, ":irc.example.com 354 TestBot 152 gina g.host gina 0"))
+	client.dispatch(mustParse(t, ":irc.example.com 354 TestBot 999 hank h.host hank hank_acct"))
+	if u, _ := client.LookupUser("frank"); u.Account != "frank_acct" || u.Hostmask() != "frank!frank@f.host" {
+		t.Errorf("Unexpected WHOX result for frank: %+v", u)
+	}
+	if u, ok := client.LookupUser("gina"); !ok || u.Account != "" {
+		t.Errorf("Expected gina to be known without an account, got %+v, %v", u, ok)
+	}
+	if _, ok := client.LookupUser("hank"); ok {
+		t.Error("Replies to other WHOX queries should be ignored")
+	}
+}
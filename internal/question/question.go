@@ -1,10 +1,14 @@
 package question
 
 import (
+	"crypto/sha1"
 	"embed"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 )
 
 //go:embed all.json
@@ -20,6 +24,24 @@ type Question struct {
 	Episode  int    `json:"episode"`
 }
 
+// Value returns the clue's dollar value parsed from Money, e.g. 1200 for
+// "$1,200". Clues without a value, such as Final Jeopardy, return 0.
+func (q *Question) Value() int {
+	money := strings.NewReplacer("$", "", ",", "").Replace(strings.TrimSpace(q.Money))
+	value, err := strconv.Atoi(money)
+	if err != nil || value < 0 {
+		return 0
+	}
+	return value
+}
+
+// ID identifies the clue by its category and text, so the same clue has
+// the same ID wherever it comes from.
+func (q *Question) ID() string {
+	sum := sha1.Sum([]byte(q.Category + "\x00" + q.Question)) // #nosec G401 -- not used for security
+	return hex.EncodeToString(sum[:6])
+}
+
 // QuestionSource defines an interface for fetching questions.
 type QuestionSource interface {
 	Next() (*Question, error)
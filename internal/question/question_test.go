@@ -0,0 +1,39 @@
+package question
+
+import "testing"
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		money    string
+		expected int
+	}{
+		{"$200", 200},
+		{"$1,200", 1200},
+		{" $400 ", 400},
+		{"2000", 2000},
+		{"None", 0},
+		{"", 0},
+		{"$-5", 0},
+	}
+	for _, tt := range tests {
+		q := &Question{Money: tt.money}
+		if got := q.Value(); got != tt.expected {
+			t.Errorf("Value() for Money %q = %d, expected %d", tt.money, got, tt.expected)
+		}
+	}
+}
+
+func TestID(t *testing.T) {
+	q := &Question{Category: "SCIENCE", Question: "This gas makes up most of the air", Answer: "nitrogen"}
+	same := &Question{Category: "SCIENCE", Question: "This gas makes up most of the air", Money: "$400"}
+	other := &Question{Category: "SCIENCE", Question: "This gas makes up most of Mars' air"}
+	if len(q.ID()) != 12 {
+		t.Errorf("ID() = %q, expected 12 characters", q.ID())
+	}
+	if q.ID() != same.ID() {
+		t.Errorf("ID() differs for the same clue: %q and %q", q.ID(), same.ID())
+	}
+	if q.ID() == other.ID() {
+		t.Errorf("ID() is %q for two different clues", q.ID())
+	}
+}